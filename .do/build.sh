@@ -4,6 +4,6 @@ echo "🔨 Building Telegram Bot for DigitalOcean App Platform"
 
 # Сборка Go приложения
 go mod tidy
-go build -o bot cmd/main.go
+go build -o bot ./cmd
 
 echo "✅ Build completed successfully"
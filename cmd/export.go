@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"telegram_verification_bot/internal/config"
+	"telegram_verification_bot/internal/export"
+	"telegram_verification_bot/internal/sheets"
+)
+
+// runExport выгружает пользователей в CSV или XLSX
+func runExport(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "csv", "формат: csv или xlsx")
+	status := flags.String("status", "", "только с этим статусом")
+	role := flags.String("role", "", "только с этой ролью")
+	from := flags.String("from", "", "дата регистрации не раньше, ГГГГ-ММ-ДД")
+	to := flags.String("to", "", "дата регистрации не позже, ГГГГ-ММ-ДД")
+	settlement := flags.String("settlement", "", "только из поселка с этим кодом, например GFC; GF* — из всех, чей код начинается с GF")
+	out := flags.String("out", "", "файл выгрузки, по умолчанию users.<формат>")
+	noPersonal := flags.Bool("no-personal", false, "не выгружать телефоны, email и комментарии")
+	flags.Parse(args)
+
+	filterArgs := []string{*format}
+	for key, value := range map[string]string{"status": *status, "role": *role, "from": *from, "to": *to, "settlement": *settlement} {
+		if value != "" {
+			filterArgs = append(filterArgs, key+"="+value)
+		}
+	}
+	filter, exportFormat, err := export.ParseFilter(filterArgs)
+	if err != nil {
+		log.Fatalf("Invalid filter: %v", err)
+	}
+
+	sheetsService, err := sheets.NewSheetsService(cfg.CredentialsPath, cfg.SpreadsheetID)
+	if err != nil {
+		log.Fatalf("Failed to create sheets service: %v", err)
+	}
+	sheetsService.SetUsersSheet(cfg.UsersSheet)
+
+	users, err := sheetsService.GetAllUsers(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to load users: %v", err)
+	}
+	users = export.Apply(users, filter)
+
+	path := *out
+	if path == "" {
+		path = "users." + string(exportFormat)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Failed to create %s: %v", path, err)
+	}
+	defer file.Close()
+
+	if err := export.Write(file, exportFormat, users, export.Fields(!*noPersonal)); err != nil {
+		log.Fatalf("Failed to write export: %v", err)
+	}
+	fmt.Printf("📤 Выгружено пользователей: %d в %s\n", len(users), path)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"os/signal"
@@ -17,6 +18,25 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Подкоманды обслуживания, без аргументов запускается бот
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sheets":
+			runSheets(cfg, os.Args[2:])
+		case "import-registry":
+			runImportRegistry(cfg, os.Args[2:])
+		case "export":
+			runExport(cfg, os.Args[2:])
+		default:
+			log.Fatalf("Unknown command %q. Available: sheets doctor [--fix], import-registry [--dry-run] FILE, export [flags]", os.Args[1])
+		}
+		return
+	}
+
+	runBot(cfg)
+}
+
+func runBot(cfg *config.Config) {
 	// Создаем и запускаем бота
 	b, err := bot.NewBot(cfg)
 	if err != nil {
@@ -30,23 +50,28 @@ func main() {
 	log.Println("  /register - start registration process")  
 	log.Println("  /status - check application status")
 	log.Println("  /help - show help")
+	log.Println("  /language - choose interface language")
 	log.Println("  /users - list all users (admin only)")
-	log.Println("  /approve ID role - approve user (admin only)")
-	log.Println("  /reject ID reason - reject user (admin only)")
+	log.Println("  /approve ID[,ID...] role - approve users (admin only)")
+	log.Println("  /reject ID[,ID...] reason - reject users (admin only)")
+	log.Println("  /bulk - bulk moderation from CSV (admin only)")
+	log.Println("  /templates - edit message templates (admin only)")
+	log.Println("  /queue - update queue depth (admin only)")
+	log.Println("  /outbox - pending writes to Google Sheets (admin only)")
+	log.Println("  /owner ID - set primary owner of the user's plot (admin only)")
+	log.Println("  /import_registry - import owners' registry from CSV (admin only)")
+	log.Println("  /export - export users to CSV or XLSX (moderators)")
+	log.Println("  /broadcast - message users by status, role and settlement (admin only)")
 	log.Println()
 	log.Println("Press Ctrl+C to stop the bot")
 
-	// Обрабатываем сигналы для корректного завершения
-	go func() {
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-		<-c
-		log.Println("🛑 Shutting down bot...")
-		os.Exit(0)
-	}()
+	// По сигналу бот перестает принимать обновления и дожидается обработчиков
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	// Запускаем бота
-	if err := b.Start(); err != nil {
+	if err := b.Start(ctx); err != nil {
 		log.Fatalf("Bot error: %v", err)
 	}
+	log.Println("Bot stopped")
 }
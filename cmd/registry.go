@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"telegram_verification_bot/internal/config"
+	"telegram_verification_bot/internal/registry"
+	"telegram_verification_bot/internal/sheets"
+)
+
+// runImportRegistry загружает реестр собственников из CSV во вкладку Registry
+func runImportRegistry(cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("import-registry", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "только проверить файл, не записывая в таблицу")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Println("Использование: bot import-registry [--dry-run] файл.csv")
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		log.Fatalf("Failed to open registry: %v", err)
+	}
+	defer file.Close()
+
+	entries, skipped, err := registry.ParseCSV(file)
+	if err != nil {
+		log.Fatalf("Failed to parse registry: %v", err)
+	}
+	fmt.Printf("📒 Записей: %d, пропущено строк без участка: %d\n", len(entries), skipped)
+	if *dryRun {
+		return
+	}
+
+	sheetsService, err := sheets.NewSheetsService(cfg.CredentialsPath, cfg.SpreadsheetID)
+	if err != nil {
+		log.Fatalf("Failed to create sheets service: %v", err)
+	}
+
+	if err := sheetsService.ReplaceRegistry(context.Background(), entries); err != nil {
+		log.Fatalf("Failed to import registry: %v", err)
+	}
+	fmt.Printf("✅ Реестр загружен во вкладку %s\n", sheets.RegistryTab)
+}
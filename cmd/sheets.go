@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"telegram_verification_bot/internal/config"
+	"telegram_verification_bot/internal/sheets"
+)
+
+// runSheets обрабатывает подкоманды "sheets ..."
+func runSheets(cfg *config.Config, args []string) {
+	if len(args) == 0 || args[0] != "doctor" {
+		fmt.Println("Использование: bot sheets doctor [--fix]")
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("sheets doctor", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "исправить заголовки, добавить вкладки и выпадающие списки")
+	flags.Parse(args[1:])
+
+	sheetsService, err := sheets.NewSheetsService(cfg.CredentialsPath, cfg.SpreadsheetID)
+	if err != nil {
+		log.Fatalf("Failed to create sheets service: %v", err)
+	}
+	sheetsService.SetUsersSheet(cfg.UsersSheet)
+
+	fmt.Println("🩺 Проверяю Google Таблицу...")
+
+	report, err := sheetsService.Doctor(context.Background(), *fix)
+	if report != nil {
+		for _, issue := range report.Issues {
+			fmt.Printf("⚠️  %s\n", issue)
+		}
+		for _, fixed := range report.Fixed {
+			fmt.Printf("🔧 %s\n", fixed)
+		}
+	}
+	if err != nil {
+		log.Fatalf("Sheets doctor failed: %v", err)
+	}
+
+	if len(report.Issues) == 0 {
+		fmt.Println("✅ Таблица соответствует схеме")
+	} else if !*fix {
+		fmt.Println("Запустите с --fix, чтобы исправить заголовки и вкладки. Ошибки в данных исправляются вручную.")
+	}
+	fmt.Printf("Таблица: https://docs.google.com/spreadsheets/d/%s/edit\n", cfg.SpreadsheetID)
+
+	if len(report.Issues) > 0 && !*fix {
+		os.Exit(1)
+	}
+}
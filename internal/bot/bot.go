@@ -1,25 +1,53 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
 	"telegram_verification_bot/internal/config"
+	"telegram_verification_bot/internal/i18n"
 	"telegram_verification_bot/internal/models"
+	"telegram_verification_bot/internal/outbox"
+	"telegram_verification_bot/internal/rules"
 	"telegram_verification_bot/internal/sheets"
+	"telegram_verification_bot/internal/templates"
 )
 
+const defaultSyncInterval = time.Minute
+
 type Bot struct {
 	api            *tgbotapi.BotAPI
 	config         *config.Config
 	sheets         *sheets.SheetsService
 	registrations  map[int64]*models.RegistrationState
 	mutex          sync.RWMutex
+	relay          *relayManager
+	outbox         *outbox.Outbox
+	rules          *rules.Engine
+	bulk           *pendingStore[*bulkBatch]
+	server         *http.Server   // сервер webhook, nil в режиме long polling
+	handlers       sync.WaitGroup // фоновые задачи, запущенные через spawn
+	dispatcher     *dispatcher
+	router         *router
+	codec          *callbackdata.Codec
+	i18n           *i18n.Catalog
+	languages      *languageStore
+	limiter        *rateLimiter
+	templates      *templates.Set
+	templateDrafts *pendingStore[*templateDraft]
+	sender         *sender
+	blocked        *blockedStore
+	broadcasts     *broadcastManager
+	vouchMutex     sync.Mutex      // проверка подтверждений соседей идет по одной заявке за раз
+	ctx            context.Context // отменяется при остановке, прерывает фоновые задачи и запросы к таблице
 }
 
 func NewBot(cfg *config.Config) (*Bot, error) {
@@ -32,61 +60,170 @@ func NewBot(cfg *config.Config) (*Bot, error) {
 	if err != nil {
 		return nil, err
 	}
+	sheetsService.SetRetryPolicy(cfg.SheetsMaxAttempts, cfg.SheetsRequestsPerMinute)
+	sheetsService.SetUsersSheet(cfg.UsersSheet)
+
+	if cfg.CacheTTLSeconds > 0 {
+		sheetsService.SetCacheTTL(time.Duration(cfg.CacheTTLSeconds) * time.Second)
+	}
+
+	outboxPath := cfg.OutboxPath
+	if outboxPath == "" {
+		outboxPath = defaultOutboxPath
+	}
+	pending, err := outbox.Open(outboxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := rules.New(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog, err := i18n.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	messageTemplates, err := configTemplates(cfg, catalog.Languages())
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Reminders.DigestTime != "" {
+		if _, _, err := parseClock(cfg.Reminders.DigestTime); err != nil {
+			return nil, fmt.Errorf("reminders.digest_time: %v", err)
+		}
+	}
 
 	api.Debug = false
 	log.Printf("Authorized on account %s", api.Self.UserName)
 
-	return &Bot{
-		api:           api,
-		config:        cfg,
-		sheets:        sheetsService,
-		registrations: make(map[int64]*models.RegistrationState),
-	}, nil
+	b := &Bot{
+		api:            api,
+		config:         cfg,
+		sheets:         sheetsService,
+		registrations:  make(map[int64]*models.RegistrationState),
+		relay:          newRelayManager(cfg.RelayLimit, time.Duration(cfg.RelayWindowMinutes)*time.Minute),
+		outbox:         pending,
+		rules:          engine,
+		bulk:           newPendingStore[*bulkBatch](pendingTTL),
+		dispatcher:     newDispatcher(cfg.Workers, cfg.QueueSize),
+		codec:          newCallbackCodec(cfg),
+		i18n:           catalog,
+		languages:      newLanguageStore(),
+		limiter:        newRateLimiter(cfg.RateLimit, time.Duration(cfg.RateWindowSeconds)*time.Second),
+		templates:      messageTemplates,
+		templateDrafts: newPendingStore[*templateDraft](pendingTTL),
+		blocked:        newBlockedStore(),
+		broadcasts:     newBroadcastManager(),
+		ctx:            context.Background(),
+	}
+	b.sender = newSender(api, cfg.SendMaxAttempts, b.markBlocked)
+	b.router = b.routes()
+	return b, nil
 }
 
-func (b *Bot) Start() error {
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
+// Start принимает обновления до отмены ctx, затем корректно останавливает бота
+func (b *Bot) Start(ctx context.Context) error {
+	b.ctx = ctx
 
-	updates := b.api.GetUpdatesChan(u)
+	if err := b.loadDrafts(); err != nil {
+		log.Printf("Error restoring registration drafts: %v", err)
+	}
+	if err := b.loadLanguages(); err != nil {
+		log.Printf("Error loading user languages: %v", err)
+	}
+	if err := b.loadTemplates(); err != nil {
+		log.Printf("Error loading message templates: %v", err)
+	}
+	if err := b.loadBlocked(); err != nil {
+		log.Printf("Error loading blocked users: %v", err)
+	}
 
-	for update := range updates {
-		if update.Message != nil {
-			go b.handleMessage(update.Message)
-		} else if update.CallbackQuery != nil {
-			go b.handleCallbackQuery(update.CallbackQuery)
+	updates, err := b.receiveUpdates()
+	if err != nil {
+		return err
+	}
+
+	go b.syncSheets(ctx)
+	go b.outbox.Run(ctx, b.applyOutboxItem)
+	go b.runReminders(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Shutting down bot...")
+			b.shutdown(updates)
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			b.dispatch(update)
 		}
 	}
+}
+
+// syncSheets периодически сверяет кэш с таблицей, чтобы подхватывать ручные правки
+func (b *Bot) syncSheets(ctx context.Context) {
+	interval := time.Duration(b.config.SyncIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultSyncInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := b.sheets.Sync(ctx)
+		if err != nil {
+			log.Printf("Error syncing sheets: %v", err)
+			continue
+		}
+		if changed {
+			log.Println("Sheet edits detected, user cache reloaded")
+		}
 
-	return nil
+		b.purgeDecidedDocuments()
+
+		if err := b.loadTemplates(); err != nil {
+			log.Printf("Error reloading message templates: %v", err)
+		}
+	}
 }
 
 func (b *Bot) handleMessage(message *tgbotapi.Message) {
-	userID := message.From.ID
-
 	// Устанавливаем меню для новых пользователей
 	b.ensureMenuSet(message)
+	// Пользователь, который пишет боту, снова может получать сообщения
+	b.markReachable(message.From.ID)
 
-	// Обработка команд и кнопок меню
-	if message.IsCommand() || b.isMenuButton(message.Text) {
-		switch {
-		case message.Command() == "start" || message.Text == "🏠 Меню":
-			b.handleStart(message)
-		case message.Command() == "register" || message.Text == "📝 Регистрация":
-			b.handleRegister(message)
-		case message.Command() == "status" || message.Text == "📊 Статус":
-			b.handleStatus(message)
-		case message.Command() == "help" || message.Text == "❓ Справка":
-			b.handleHelp(message)
-		case message.Text == "👥 Пользователи" && message.From.ID == b.config.AdminID:
-			b.handleListUsers(message)
-		case message.Text == "🔍 Поиск" && message.From.ID == b.config.AdminID:
-			b.handleAdminSearchMode(message)
-		case message.Command() == "approve" || message.Command() == "reject":
-			b.handleModeration(message)
-		case message.Command() == "users":
-			b.handleListUsers(message)
-		}
+	req := messageRequest(message)
+	req.lang = b.lang(message.From)
+	b.router.serve(req)
+}
+
+// handleText обрабатывает сообщение без команды и кнопки меню
+func (b *Bot) handleText(message *tgbotapi.Message) {
+	userID := message.From.ID
+
+	// Сообщение для рассылки от администратора
+	if userID == b.config.AdminID && b.broadcasts.isComposing(userID) {
+		b.handleBroadcastContent(message)
+		return
+	}
+
+	// Пересылка сообщения соседу
+	if b.relay.hasDraft(userID) {
+		b.handleRelayMessage(message)
 		return
 	}
 
@@ -103,37 +240,36 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) {
 	b.handleSearch(message)
 }
 
-func (b *Bot) handleStart(message *tgbotapi.Message) {
-	text := `👋 Добро пожаловать в бот верификации!
-
-Выберите действие:`
+func (b *Bot) handleStart(req *request) {
+	data := templates.Data{
+		TelegramID: req.from.ID,
+		Username:   req.from.UserName,
+		FirstName:  req.from.FirstName,
+		LastName:   req.from.LastName,
+	}
+	if user, err := b.getUser(req.from.ID); err == nil {
+		data = templates.FromUser(user)
+		data.Role = b.roleName(req.lang, user.Role)
+	}
+	text := b.renderTemplate(templates.Welcome, req.lang, data, b.i18n.T(req.lang, "start.welcome"))
 
 	// Создаем постоянное меню
-	keyboard := b.createPermanentMenu(message.From.ID)
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	keyboard := b.createPermanentMenu(req.lang, req.from.ID)
+	msg := tgbotapi.NewMessage(req.chatID, text)
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.send(msg)
 }
 
-func (b *Bot) handleRegister(message *tgbotapi.Message) {
-	userID := message.From.ID
+func (b *Bot) handleRegister(req *request) {
+	userID := req.from.ID
 
 	// Проверяем, не зарегистрирован ли уже пользователь
-	existingUser, _ := b.sheets.GetUser(userID)
+	existingUser, _ := b.getUser(userID)
 	if existingUser != nil {
-		var statusText string
-		switch existingUser.Status {
-		case models.StatusPending:
-			statusText = "⏳ На рассмотрении"
-		case models.StatusApproved:
-			statusText = fmt.Sprintf("✅ Одобрена (роль: %s)", existingUser.Role)
-		case models.StatusRejected:
-			statusText = "❌ Отклонена"
-		}
-
-		text := fmt.Sprintf("Вы уже зарегистрированы!\nСтатус заявки: %s", statusText)
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		statusText := b.statusText(req.lang, existingUser)
+		text := b.i18n.T(req.lang, "register.already", statusText)
+		msg := tgbotapi.NewMessage(req.chatID, text)
+		b.send(msg)
 		return
 	}
 
@@ -143,7 +279,7 @@ func (b *Bot) handleRegister(message *tgbotapi.Message) {
 		Step:       models.StepFirstName,
 		User: models.User{
 			TelegramID:   userID,
-			Username:     message.From.UserName,
+			Username:     req.from.UserName,
 			RegisterDate: time.Now(),
 			Status:       models.StatusPending,
 			Role:         models.RoleGuest,
@@ -154,320 +290,378 @@ func (b *Bot) handleRegister(message *tgbotapi.Message) {
 	b.registrations[userID] = reg
 	b.mutex.Unlock()
 
-	text := "📝 Начинаем процесс регистрации!\n\n👤 Пожалуйста, введите ваше имя\n*в формате:* Иван"
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	text := b.i18n.T(req.lang, "register.first_name")
+	msg := tgbotapi.NewMessage(req.chatID, text)
 	msg.ParseMode = "Markdown"
-	b.api.Send(msg)
+	b.send(msg)
 }
 
 func (b *Bot) handleRegistrationStep(message *tgbotapi.Message, reg *models.RegistrationState) {
 	userID := message.From.ID
+	lang := b.lang(message.From)
 
 	switch reg.Step {
 	case models.StepFirstName:
 		reg.User.FirstName = message.Text
 		reg.Step = models.StepLastName
-		text := "✅ Отлично! Теперь введите вашу фамилию\n*в формате:* Иванов"
+		text := b.i18n.T(lang, "register.last_name")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		msg.ParseMode = "Markdown"
-		b.api.Send(msg)
+		b.send(msg)
 
 	case models.StepLastName:
 		reg.User.LastName = message.Text
 		reg.Step = models.StepPhone
-		text := "✅ Хорошо! Введите ваш номер телефона\n*в формате:* +71234567890"
+		text := b.i18n.T(lang, "register.phone")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		msg.ParseMode = "Markdown"
-		b.api.Send(msg)
+		b.send(msg)
 
 	case models.StepPhone:
 		reg.User.Phone = message.Text
 		reg.Step = models.StepEmail
-		text := "✅ Принято! Введите ваш email\n*пример:* example@mail.com"
+		text := b.i18n.T(lang, "register.email")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		msg.ParseMode = "Markdown"
-		b.api.Send(msg)
+		b.send(msg)
 
 	case models.StepEmail:
 		reg.User.Email = message.Text
 		reg.Step = models.StepAddress
-		text := `✅ Отлично! И наконец, введите ваш адрес по образцу:
-
-🏘 *Поселок Green Forest Club:* GFC P11
-🏘 *Поселок Green Forest Park:* GFP P11
-🏘 *Green Forest Premium:* GFPr P11`
+		text := b.i18n.T(lang, "register.address")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
 		msg.ParseMode = "Markdown"
-		b.api.Send(msg)
+		b.send(msg)
 
 	case models.StepAddress:
 		reg.User.Address = message.Text
-		reg.Step = models.StepComplete
+		reg.Step = models.StepVouch
+		text := b.i18n.N(lang, "register.vouchers", maxVouchers)
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+
+	case models.StepVouch:
+		if input := strings.TrimSpace(message.Text); input != "-" {
+			found, notFound := b.resolveVouchers(userID, input)
+			if len(notFound) > 0 {
+				text := b.i18n.T(lang, "register.vouchers_not_found", strings.Join(notFound, ", "))
+				msg := tgbotapi.NewMessage(message.Chat.ID, text)
+				b.send(msg)
+				return
+			}
+			reg.Vouchers = nil
+			for _, voucher := range found {
+				reg.Vouchers = append(reg.Vouchers, voucher.TelegramID)
+			}
+		}
+		reg.Step = models.StepDocuments
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.N(lang, "documents.prompt", maxDocuments))
+		b.send(msg)
 
-		// Сохраняем пользователя в Google Sheets
-		err := b.sheets.AddUser(&reg.User)
-		if err != nil {
-			log.Printf("Error adding user to sheets: %v", err)
-			text := "❌ Произошла ошибка при сохранении данных. Попробуйте позже."
-			msg := tgbotapi.NewMessage(message.Chat.ID, text)
-			b.api.Send(msg)
+	case models.StepDocuments:
+		if !b.handleDocumentStep(message, reg) {
 			return
 		}
+		reg.Step = models.StepComplete
+		b.completeRegistration(message, reg)
+	}
+}
 
-		// Удаляем состояние регистрации
-		b.mutex.Lock()
-		delete(b.registrations, userID)
-		b.mutex.Unlock()
+// completeRegistration сохраняет заявку и рассылает уведомления модератору и соседям
+func (b *Bot) completeRegistration(message *tgbotapi.Message, reg *models.RegistrationState) {
+	userID := message.From.ID
+	lang := b.lang(message.From)
 
-		// Отправляем подтверждение пользователю
-		text := fmt.Sprintf(`✅ Регистрация завершена!
+	// Сохраняем заявку в локальный журнал, в Google Sheets ее допишет фоновый обработчик
+	user := reg.User
+	err := b.outbox.Enqueue(&outbox.Item{
+		Kind:       outbox.KindAddUser,
+		TelegramID: userID,
+		User:       &user,
+	})
+	if err != nil {
+		log.Printf("Error saving registration to outbox: %v", err)
+		text := b.i18n.T(lang, "register.save_error")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
 
-📋 Ваши данные:
-👤 Имя: %s %s
-📱 Телефон: %s
-📧 Email: %s
-🏠 Адрес: %s
+	// Удаляем состояние регистрации
+	b.mutex.Lock()
+	delete(b.registrations, userID)
+	b.mutex.Unlock()
 
-⏳ Ваша заявка отправлена на модерацию. Ожидайте уведомления о результате.`, 
-			reg.User.FirstName, reg.User.LastName, reg.User.Phone, reg.User.Email, reg.User.Address)
+	// Отправляем подтверждение пользователю
+	data := templates.FromUser(&reg.User)
+	data.Role = b.roleName(lang, reg.User.Role)
+	text := b.renderTemplate(templates.Registered, lang, data, b.i18n.T(lang, "register.complete",
+		reg.User.FirstName, reg.User.LastName, reg.User.Phone, reg.User.Email, reg.User.Address))
 
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	b.send(msg)
 
-		// Отправляем уведомление администратору
-		b.sendAdminNotification(&reg.User)
-	}
+	// Запросы соседям записываются до уведомления, чтобы попасть в карточку модератора
+	b.requestNeighborVouches(&reg.User, reg.Vouchers)
+
+	// Отправляем уведомление администратору, следом — приложенные документы
+	b.sendAdminNotification(&reg.User)
+	b.forwardDocuments(&reg.User, reg.Documents)
+
+	// Просим владельца участка подтвердить члена домохозяйства
+	b.requestOwnerVouch(&reg.User)
+
+	// Очевидные заявки решаются правилами без модератора
+	b.applyRules(&reg.User)
 }
 
 func (b *Bot) sendAdminNotification(user *models.User) {
-	text := fmt.Sprintf(`🆕 Новая заявка на верификацию!
-
-👤 Пользователь: %s %s (@%s)
-📱 ID: %d
-📞 Телефон: %s
-📧 Email: %s
-🏠 Адрес: %s
-📅 Дата: %s`,
+	lang := b.langOf(b.config.AdminID)
+	text := b.i18n.T(lang, "moderation.card",
 		user.FirstName, user.LastName, user.Username, user.TelegramID,
 		user.Phone, user.Email, user.Address,
 		user.RegisterDate.Format("2006-01-02 15:04:05"))
 
+	// Предупреждаем о похожих записях с других аккаунтов
+	text += b.formatDuplicates(lang, b.findDuplicates(user))
+	text += b.formatHousehold(lang, user)
+	text += b.formatVouches(lang, user)
+	text += b.formatRegistry(lang, user)
+
 	// Создаем кнопки для быстрой модерации
-	keyboard := b.createModerationMenu(user.TelegramID)
+	keyboard := b.createModerationMenu(lang, user.TelegramID)
 
 	msg := tgbotapi.NewMessage(b.config.AdminID, text)
 	msg.ReplyMarkup = keyboard
-	b.api.Send(msg)
+	b.send(msg)
+}
+
+// moderatorName возвращает подпись модератора для колонки "Кем обновлено"
+func moderatorName(user *tgbotapi.User) string {
+	if user.UserName != "" {
+		return fmt.Sprintf("@%s (%d)", user.UserName, user.ID)
+	}
+	return fmt.Sprintf("%s (%d)", strings.TrimSpace(user.FirstName+" "+user.LastName), user.ID)
 }
 
 // createModerationMenu создает меню модерации для админа
-func (b *Bot) createModerationMenu(userID int64) tgbotapi.InlineKeyboardMarkup {
+func (b *Bot) createModerationMenu(lang string, userID int64) tgbotapi.InlineKeyboardMarkup {
 	row1 := []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("✅ Житель", fmt.Sprintf("approve_%d_житель", userID)),
-		tgbotapi.NewInlineKeyboardButtonData("✅ Сосед", fmt.Sprintf("approve_%d_сосед", userID)),
-		tgbotapi.NewInlineKeyboardButtonData("✅ ОК", fmt.Sprintf("approve_%d_ОК", userID)),
+		b.actionButton(b.i18n.T(lang, "moderation.approve_resident"), "approve", userID, string(models.RoleResident)),
+		b.actionButton(b.i18n.T(lang, "moderation.approve_neighbor"), "approve", userID, string(models.RoleNeighbor)),
+		b.actionButton(b.i18n.T(lang, "moderation.approve_ok"), "approve", userID, string(models.RoleOK)),
 	}
 	row2 := []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("❌ Отклонить", fmt.Sprintf("reject_%d", userID)),
+		b.actionButton(b.i18n.T(lang, "moderation.reject"), "reject", userID),
 	}
 
 	return tgbotapi.NewInlineKeyboardMarkup(row1, row2)
 }
 
-func (b *Bot) handleStatus(message *tgbotapi.Message) {
-	userID := message.From.ID
+func (b *Bot) handleStatus(req *request) {
+	userID := req.from.ID
 
-	user, err := b.sheets.GetUser(userID)
+	user, err := b.getUser(userID)
 	if err != nil {
-		text := "❓ Вы не найдены в системе. Используйте /register для регистрации."
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		text := b.i18n.T(req.lang, "status.not_found")
+		msg := tgbotapi.NewMessage(req.chatID, text)
+		b.send(msg)
 		return
 	}
 
-	var statusText string
-	switch user.Status {
-	case models.StatusPending:
-		statusText = "⏳ На рассмотрении"
-	case models.StatusApproved:
-		statusText = fmt.Sprintf("✅ Одобрена (роль: %s)", user.Role)
-	case models.StatusRejected:
-		statusText = "❌ Отклонена"
-		if user.AdminComment != "" {
-			statusText += fmt.Sprintf("\nПричина: %s", user.AdminComment)
-		}
+	statusText := b.statusText(req.lang, user)
+	if user.Status == models.StatusRejected && user.AdminComment != "" {
+		statusText += b.i18n.T(req.lang, "status.reason", user.AdminComment)
 	}
 
-	text := fmt.Sprintf(`📋 Статус вашей заявки: %s
-
-👤 Имя: %s %s
-📧 Email: %s
-📅 Дата регистрации: %s`, 
+	text := b.i18n.T(req.lang, "status.card",
 		statusText, user.FirstName, user.LastName, user.Email,
 		user.RegisterDate.Format("2006-01-02 15:04"))
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
-	b.api.Send(msg)
+	msg := tgbotapi.NewMessage(req.chatID, text)
+	b.send(msg)
 }
 
-func (b *Bot) handleModeration(message *tgbotapi.Message) {
-	// Только администратор может модерировать
-	if message.From.ID != b.config.AdminID {
-		text := "❌ У вас нет прав для выполнения этой команды."
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
-		return
+// statusText описывает статус заявки на языке lang
+func (b *Bot) statusText(lang string, user *models.User) string {
+	switch user.Status {
+	case models.StatusPending:
+		return b.i18n.T(lang, "status.pending")
+	case models.StatusApproved:
+		return b.i18n.T(lang, "status.approved", b.roleName(lang, user.Role))
+	case models.StatusRejected:
+		return b.i18n.T(lang, "status.rejected")
 	}
+	return string(user.Status)
+}
 
+func (b *Bot) handleModeration(message *tgbotapi.Message) {
+	lang := b.lang(message.From)
 	args := strings.Fields(message.Text)
 	if len(args) < 2 {
-		text := "❌ Неверный формат команды.\nИспользуйте: /approve ID роль или /reject ID причина"
+		text := b.i18n.T(lang, "moderation.usage")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
-	userID, err := strconv.ParseInt(args[1], 10, 64)
+	userIDs, err := parseModerationIDs(args[1])
+	rest := args[2:]
 	if err != nil {
-		text := "❌ Неверный ID пользователя."
+		text := b.i18n.T(lang, "moderation.bad_id")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
+	var status models.UserStatus
+	var role models.UserRole
+	var reason, comment string
+
 	if message.Command() == "approve" {
-		if len(args) < 3 {
-			text := "❌ Укажите роль: житель, сосед, ОК"
+		if len(rest) < 1 {
+			text := b.i18n.T(lang, "moderation.role_required")
 			msg := tgbotapi.NewMessage(message.Chat.ID, text)
-			b.api.Send(msg)
+			b.send(msg)
 			return
 		}
 
-		role := models.UserRole(args[2])
+		role = models.UserRole(rest[0])
 		if role != models.RoleResident && role != models.RoleNeighbor && role != models.RoleOK {
-			text := "❌ Недопустимая роль. Используйте: житель, сосед, ОК"
+			text := b.i18n.T(lang, "moderation.bad_role")
 			msg := tgbotapi.NewMessage(message.Chat.ID, text)
-			b.api.Send(msg)
+			b.send(msg)
 			return
 		}
+		status = models.StatusApproved
+	} else {
+		// Без причины пользователь получит «не указана» на своем языке,
+		// а в таблицу она попадет на языке по умолчанию
+		reason = strings.Join(rest, " ")
+		comment = reason
+		if comment == "" {
+			comment = b.i18n.T(i18n.DefaultLanguage, "reason.unspecified")
+		}
+		status = models.StatusRejected
+		role = models.RoleGuest
+	}
 
-		err = b.sheets.UpdateUserStatus(userID, models.StatusApproved, role, "")
+	var done []string
+	var failed []string
+	var undelivered []string
+	for _, userID := range userIDs {
+		err := b.setUserStatus(userID, status, role, comment, moderatorName(message.From))
 		if err != nil {
-			text := "❌ Ошибка при обновлении статуса."
-			msg := tgbotapi.NewMessage(message.Chat.ID, text)
-			b.api.Send(msg)
-			return
+			failed = append(failed, fmt.Sprintf("%d: %s", userID, b.statusErrorText(lang, err)))
+			continue
 		}
 
 		// Уведомляем пользователя
-		userMsg := tgbotapi.NewMessage(userID, fmt.Sprintf("🎉 Ваша заявка одобрена!\nВаша роль: %s", role))
-		b.api.Send(userMsg)
-
-		// Подтверждаем админу
-		text := fmt.Sprintf("✅ Пользователь %d одобрен с ролью: %s", userID, role)
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
-
-	} else if message.Command() == "reject" {
-		reason := strings.Join(args[2:], " ")
-		if reason == "" {
-			reason = "Не указана"
+		if err := b.notifyDecision(userID, status, role, reason); err != nil {
+			undelivered = append(undelivered, fmt.Sprintf("%d: %s", userID, b.sendErrorText(lang, err)))
 		}
+		done = append(done, strconv.FormatInt(userID, 10))
+	}
 
-		err = b.sheets.UpdateUserStatus(userID, models.StatusRejected, models.RoleGuest, reason)
-		if err != nil {
-			text := "❌ Ошибка при обновлении статуса."
-			msg := tgbotapi.NewMessage(message.Chat.ID, text)
-			b.api.Send(msg)
-			return
+	// Подтверждаем админу
+	var text string
+	if status == models.StatusApproved {
+		text = b.i18n.T(lang, "moderation.approved", strings.Join(done, ", "), b.roleName(lang, role))
+		if len(done) > 1 {
+			text = b.i18n.T(lang, "moderation.approved_many", len(done), b.roleName(lang, role), strings.Join(done, ", "))
+		}
+	} else {
+		shown := reason
+		if shown == "" {
+			shown = b.i18n.T(lang, "reason.unspecified")
+		}
+		text = b.i18n.T(lang, "moderation.rejected", strings.Join(done, ", "), shown)
+		if len(done) > 1 {
+			text = b.i18n.T(lang, "moderation.rejected_many", len(done), strings.Join(done, ", "), shown)
 		}
-
-		// Уведомляем пользователя
-		userMsg := tgbotapi.NewMessage(userID, fmt.Sprintf("❌ Ваша заявка отклонена.\nПричина: %s", reason))
-		b.api.Send(userMsg)
-
-		// Подтверждаем админу
-		text := fmt.Sprintf("❌ Пользователь %d отклонен. Причина: %s", userID, reason)
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
 	}
-}
-
-func (b *Bot) handleListUsers(message *tgbotapi.Message) {
-	// Только администратор может просматривать список
-	if message.From.ID != b.config.AdminID {
-		text := "❌ У вас нет прав для выполнения этой команды."
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
-		return
+	if len(done) == 0 {
+		text = ""
 	}
+	if len(failed) > 0 {
+		text = strings.TrimSpace(text + "\n\n" + strings.Join(failed, "\n"))
+	}
+	if len(undelivered) > 0 {
+		text += "\n\n" + b.i18n.T(lang, "moderation.undelivered") + "\n" + strings.Join(undelivered, "\n")
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	b.send(msg)
+}
 
-	users, err := b.sheets.GetAllUsers()
+func (b *Bot) handleListUsers(req *request) {
+	users, err := b.sheets.GetAllUsers(b.ctx)
 	if err != nil {
-		text := "❌ Ошибка при получении списка пользователей."
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		text := b.i18n.T(req.lang, "users.error")
+		msg := tgbotapi.NewMessage(req.chatID, text)
+		b.send(msg)
 		return
 	}
 
 	if len(users) == 0 {
-		text := "📝 Список пользователей пуст."
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		text := b.i18n.T(req.lang, "users.empty")
+		msg := tgbotapi.NewMessage(req.chatID, text)
+		b.send(msg)
 		return
 	}
 
-	text := "👥 Список пользователей:\n\n"
+	text := b.i18n.T(req.lang, "users.header")
 	for i, user := range users {
 		status := string(user.Status)
 		switch user.Status {
 		case models.StatusPending:
-			status = "⏳ На рассмотрении"
+			status = b.i18n.T(req.lang, "users.pending")
 		case models.StatusApproved:
-			status = "✅ Одобрен"
+			status = b.i18n.T(req.lang, "users.approved")
 		case models.StatusRejected:
-			status = "❌ Отклонен"
+			status = b.i18n.T(req.lang, "users.rejected")
 		}
 
-		text += fmt.Sprintf("%d. %s %s (@%s)\n   ID: %d | %s | Роль: %s\n\n",
+		text += b.i18n.T(req.lang, "users.item",
 			i+1, user.FirstName, user.LastName, user.Username,
-			user.TelegramID, status, user.Role)
+			user.TelegramID, status, b.roleName(req.lang, user.Role))
 
 		// Telegram ограничивает размер сообщения
 		if len(text) > 3500 {
-			msg := tgbotapi.NewMessage(message.Chat.ID, text)
-			b.api.Send(msg)
+			msg := tgbotapi.NewMessage(req.chatID, text)
+			b.send(msg)
 			text = ""
 		}
 	}
 
 	if text != "" {
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		msg := tgbotapi.NewMessage(req.chatID, text)
+		b.send(msg)
 	}
 }
 
 func (b *Bot) handleSearch(message *tgbotapi.Message) {
 	// Проверяем, зарегистрирован ли пользователь
 	userID := message.From.ID
-	currentUser, err := b.sheets.GetUser(userID)
+	lang := b.lang(message.From)
+	currentUser, err := b.getUser(userID)
 	if err != nil || currentUser.Status != models.StatusApproved {
-		text := "❓ Для использования поиска необходимо пройти верификацию. Используйте /register"
+		text := b.i18n.T(lang, "search.not_verified")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
 	query := strings.ToLower(message.Text)
-	users, err := b.sheets.GetAllUsers()
+	users, err := b.sheets.GetAllUsers(b.ctx)
 	if err != nil {
-		text := "❌ Ошибка при поиске."
+		text := b.i18n.T(lang, "search.error")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
-	var results []string
+	var matched []*models.User
 	for _, user := range users {
 		if user.Status != models.StatusApproved {
 			continue
@@ -478,130 +672,95 @@ func (b *Bot) handleSearch(message *tgbotapi.Message) {
 			user.FirstName, user.LastName, user.Username, user.Phone, user.Email, user.Address))
 
 		if strings.Contains(searchText, query) {
-			result := fmt.Sprintf("👤 %s %s (@%s)\n🏠 %s | Роль: %s",
-				user.FirstName, user.LastName, user.Username, user.Address, user.Role)
-			results = append(results, result)
+			matched = append(matched, user)
 		}
 
-		if len(results) >= 10 { // Ограничиваем количество результатов
+		if len(matched) >= 10 { // Ограничиваем количество результатов
 			break
 		}
 	}
 
-	if len(results) == 0 {
-		text := "🔍 По вашему запросу ничего не найдено."
+	if len(matched) == 0 {
+		text := b.i18n.T(lang, "search.not_found")
 		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
-	text := fmt.Sprintf("🔍 Результаты поиска по запросу \"%s\":\n\n%s",
-		message.Text, strings.Join(results, "\n\n"))
+	// Группируем результаты по домохозяйствам (участкам)
+	owners, err := b.sheets.GetHouseholdOwners(b.ctx)
+	if err != nil {
+		log.Printf("Error loading household owners: %v", err)
+	}
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
-	b.api.Send(msg)
-}
+	var results []string
+	var buttons [][]tgbotapi.InlineKeyboardButton
+	for _, household := range models.GroupHouseholds(matched, owners) {
+		plot := household.Plot
+		if plot == "" {
+			plot = b.i18n.T(lang, "search.no_address")
+		}
+		result := "🏡 " + plot
 
-func (b *Bot) handleHelp(message *tgbotapi.Message) {
-	text := `📚 Справка по боту
+		for _, user := range household.Members {
+			result += b.i18n.T(lang, "search.member",
+				user.FirstName, user.LastName, user.Username, b.roleName(lang, user.Role))
+			if user.TelegramID == household.OwnerID {
+				result += b.i18n.T(lang, "search.owner")
+			}
 
-👥 Основные команды:
-🔹 /start - приветствие и основная информация
-🔹 /register - начать процесс регистрации
-🔹 /status - проверить статус заявки
-🔹 /help - эта справка
+			// Кнопка связи через бота, без раскрытия Telegram ID
+			if user.TelegramID != userID {
+				label := strings.TrimSpace(user.FirstName + " " + user.LastName)
+				buttons = append(buttons, tgbotapi.NewInlineKeyboardRow(
+					b.createRelayButton(lang, userID, user.TelegramID, label),
+				))
+			}
+		}
+		results = append(results, result)
+	}
 
-🔍 Поиск:
-После одобрения заявки вы можете искать других пользователей, просто отправив текстовое сообщение.
+	text := b.i18n.N(lang, "search.results", len(matched), message.Text, strings.Join(results, "\n\n"))
 
-👨‍💼 Команды администратора:
-🔹 /users - список всех пользователей
-🔹 /approve ID роль - одобрить заявку
-🔹 /reject ID причина - отклонить заявку
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	if len(buttons) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(buttons...)
+	}
+	b.send(msg)
+}
 
-📝 Доступные роли: житель, сосед, ОК`
+func (b *Bot) handleHelp(req *request) {
+	text := b.i18n.T(req.lang, "help.user")
+	if _, ok := b.config.Access(req.from.ID); ok {
+		text += b.i18n.T(req.lang, "help.admin")
+	}
 
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
-	b.api.Send(msg)
+	msg := tgbotapi.NewMessage(req.chatID, text)
+	b.send(msg)
 }
 
 func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
-	userID := callback.From.ID
-	data := callback.Data
-
 	// Отвечаем на callback
 	msg := tgbotapi.NewCallback(callback.ID, "")
 	b.api.Request(msg)
+	b.markReachable(callback.From.ID)
 
-	// Обрабатываем действия
-	switch data {
-	case "register":
-		// Создаем фейковое сообщение для обработки регистрации
-		fakeMsg := &tgbotapi.Message{
-			From: callback.From,
-			Chat: callback.Message.Chat,
-			Text: "/register",
-		}
-		b.handleRegister(fakeMsg)
-
-	case "status":
-		fakeMsg := &tgbotapi.Message{
-			From: callback.From,
-			Chat: callback.Message.Chat,
-			Text: "/status",
-		}
-		b.handleStatus(fakeMsg)
-
-	case "help":
-		fakeMsg := &tgbotapi.Message{
-			From: callback.From,
-			Chat: callback.Message.Chat,
-			Text: "/help",
-		}
-		b.handleHelp(fakeMsg)
-
-	case "admin_users":
-		if userID == b.config.AdminID {
-			fakeMsg := &tgbotapi.Message{
-				From: callback.From,
-				Chat: callback.Message.Chat,
-				Text: "/users",
-			}
-			b.handleListUsers(fakeMsg)
-		}
-
-	case "admin_search":
-		if userID == b.config.AdminID {
-			text := "🔍 Введите запрос для поиска пользователей (имя, фамилия, телефон, email, адрес):"
-			msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
-			b.api.Send(msg)
-		}
-
-	// Обработка модерации через кнопки
-	default:
-		if strings.HasPrefix(data, "approve_") {
-			if userID == b.config.AdminID {
-				b.handleInlineApproval(callback)
-			}
-		} else if strings.HasPrefix(data, "reject_") {
-			if userID == b.config.AdminID {
-				b.handleInlineRejection(callback)
-			}
-		}
-	}
+	req := callbackRequest(callback)
+	req.lang = b.lang(callback.From)
+	b.router.serve(req)
 }
 
 // createMainMenu создает основное меню для пользователей
-func (b *Bot) createMainMenu(userID int64) tgbotapi.InlineKeyboardMarkup {
+func (b *Bot) createMainMenu(lang string, userID int64) tgbotapi.InlineKeyboardMarkup {
 	var buttons [][]tgbotapi.InlineKeyboardButton
 
 	// Основные кнопки для всех пользователей
 	row1 := []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("📝 Зарегистрироваться", "register"),
-		tgbotapi.NewInlineKeyboardButtonData("📊 Проверить статус", "status"),
+		tgbotapi.NewInlineKeyboardButtonData(b.i18n.T(lang, "inline.register"), "register"),
+		tgbotapi.NewInlineKeyboardButtonData(b.i18n.T(lang, "inline.status"), "status"),
 	}
 	row2 := []tgbotapi.InlineKeyboardButton{
-		tgbotapi.NewInlineKeyboardButtonData("❓ Справка", "help"),
+		tgbotapi.NewInlineKeyboardButtonData(b.i18n.T(lang, "inline.help"), "help"),
 	}
 
 	buttons = append(buttons, row1, row2)
@@ -609,10 +768,10 @@ func (b *Bot) createMainMenu(userID int64) tgbotapi.InlineKeyboardMarkup {
 	// Дополнительные кнопки для администратора
 	if userID == b.config.AdminID {
 		adminRow1 := []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("👥 Список пользователей", "admin_users"),
+			tgbotapi.NewInlineKeyboardButtonData(b.i18n.T(lang, "inline.users"), "admin_users"),
 		}
 		adminRow2 := []tgbotapi.InlineKeyboardButton{
-			tgbotapi.NewInlineKeyboardButtonData("🔍 Поиск пользователя", "admin_search"),
+			tgbotapi.NewInlineKeyboardButtonData(b.i18n.T(lang, "inline.search"), "admin_search"),
 		}
 		buttons = append(buttons, adminRow1, adminRow2)
 	}
@@ -621,80 +780,99 @@ func (b *Bot) createMainMenu(userID int64) tgbotapi.InlineKeyboardMarkup {
 }
 
 // handleInlineApproval обрабатывает одобрение через inline кнопки
-func (b *Bot) handleInlineApproval(callback *tgbotapi.CallbackQuery) {
-	parts := strings.Split(callback.Data, "_")
-	if len(parts) < 3 {
+func (b *Bot) handleInlineApproval(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	userID := payload.Target
+	role := models.UserRole(payload.Arg(0))
+	lang := b.lang(callback.From)
+	if !b.stillPending(callback, userID) {
 		return
 	}
 
-	userID, err := strconv.ParseInt(parts[1], 10, 64)
+	err := b.setUserStatus(userID, models.StatusApproved, role, "", moderatorName(callback.From))
 	if err != nil {
-		return
-	}
-
-	role := models.UserRole(parts[2])
-
-	err = b.sheets.UpdateUserStatus(userID, models.StatusApproved, role, "")
-	if err != nil {
-		text := "❌ Ошибка при обновлении статуса."
+		text := b.statusErrorText(lang, err)
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
 	// Уведомляем пользователя
-	userMsg := tgbotapi.NewMessage(userID, fmt.Sprintf("🎉 Ваша заявка одобрена!\nВаша роль: %s", role))
-	b.api.Send(userMsg)
+	notifyErr := b.notifyDecision(userID, models.StatusApproved, role, "")
 
 	// Обновляем сообщение админа
-	newText := fmt.Sprintf("✅ Пользователь одобрен с ролью: %s", role)
+	newText := b.i18n.T(lang, "moderation.card_approved", b.roleName(lang, role))
+	if notifyErr != nil {
+		newText += "\n" + b.i18n.T(lang, "moderation.undelivered") + " " + b.sendErrorText(lang, notifyErr)
+	}
 	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, newText)
-	b.api.Send(editMsg)
+	b.send(editMsg)
 }
 
-// handleInlineRejection обрабатывает отклонение через inline кнопки
-func (b *Bot) handleInlineRejection(callback *tgbotapi.CallbackQuery) {
-	parts := strings.Split(callback.Data, "_")
-	if len(parts) < 2 {
-		return
+// stillPending проверяет, что заявка из карточки модератора еще ждет решения.
+// Кнопки модерации не истекают, и в старой карточке они не должны менять
+// решение, принятое позже.
+func (b *Bot) stillPending(callback *tgbotapi.CallbackQuery, userID int64) bool {
+	lang := b.lang(callback.From)
+	user, err := b.getUser(userID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.statusErrorText(lang, err))
+		b.send(msg)
+		return false
+	}
+	if user.Status == models.StatusPending {
+		return true
 	}
 
-	userID, err := strconv.ParseInt(parts[1], 10, 64)
-	if err != nil {
+	text := b.i18n.T(lang, "moderation.already_decided",
+		callback.Message.Text, user.Status, b.roleName(lang, user.Role))
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	b.send(editMsg)
+	return false
+}
+
+// handleInlineRejection обрабатывает отклонение через inline кнопки
+func (b *Bot) handleInlineRejection(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	userID := payload.Target
+	lang := b.lang(callback.From)
+	if !b.stillPending(callback, userID) {
 		return
 	}
 
-	reason := "Отклонено администратором"
-	err = b.sheets.UpdateUserStatus(userID, models.StatusRejected, models.RoleGuest, reason)
+	// В таблице причина на языке по умолчанию, пользователю — на его языке
+	comment := b.i18n.T(i18n.DefaultLanguage, "reason.rejected_by_admin")
+	err := b.setUserStatus(userID, models.StatusRejected, models.RoleGuest, comment, moderatorName(callback.From))
 	if err != nil {
-		text := "❌ Ошибка при обновлении статуса."
+		text := b.statusErrorText(lang, err)
 		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
-		b.api.Send(msg)
+		b.send(msg)
 		return
 	}
 
 	// Уведомляем пользователя
-	userMsg := tgbotapi.NewMessage(userID, fmt.Sprintf("❌ Ваша заявка отклонена.\nПричина: %s", reason))
-	b.api.Send(userMsg)
+	reason := b.i18n.T(b.langOf(userID), "reason.rejected_by_admin")
+	notifyErr := b.notifyDecision(userID, models.StatusRejected, models.RoleGuest, reason)
 
 	// Обновляем сообщение админа
-	newText := fmt.Sprintf("❌ Пользователь отклонен. Причина: %s", reason)
+	newText := b.i18n.T(lang, "moderation.card_rejected", b.i18n.T(lang, "reason.rejected_by_admin"))
+	if notifyErr != nil {
+		newText += "\n" + b.i18n.T(lang, "moderation.undelivered") + " " + b.sendErrorText(lang, notifyErr)
+	}
 	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, newText)
-	b.api.Send(editMsg)
+	b.send(editMsg)
 }
 
 // createPermanentMenu создает постоянное меню с кнопками
-func (b *Bot) createPermanentMenu(userID int64) tgbotapi.ReplyKeyboardMarkup {
+func (b *Bot) createPermanentMenu(lang string, userID int64) tgbotapi.ReplyKeyboardMarkup {
 	var buttons [][]tgbotapi.KeyboardButton
 
 	// Основные кнопки для всех пользователей
 	row1 := []tgbotapi.KeyboardButton{
-		tgbotapi.NewKeyboardButton("📝 Регистрация"),
-		tgbotapi.NewKeyboardButton("📊 Статус"),
+		tgbotapi.NewKeyboardButton(b.i18n.T(lang, "button.register")),
+		tgbotapi.NewKeyboardButton(b.i18n.T(lang, "button.status")),
 	}
 	row2 := []tgbotapi.KeyboardButton{
-		tgbotapi.NewKeyboardButton("❓ Справка"),
-		tgbotapi.NewKeyboardButton("🏠 Меню"),
+		tgbotapi.NewKeyboardButton(b.i18n.T(lang, "button.help")),
+		tgbotapi.NewKeyboardButton(b.i18n.T(lang, "button.menu")),
 	}
 
 	buttons = append(buttons, row1, row2)
@@ -702,8 +880,8 @@ func (b *Bot) createPermanentMenu(userID int64) tgbotapi.ReplyKeyboardMarkup {
 	// Дополнительные кнопки для администратора
 	if userID == b.config.AdminID {
 		adminRow := []tgbotapi.KeyboardButton{
-			tgbotapi.NewKeyboardButton("👥 Пользователи"),
-			tgbotapi.NewKeyboardButton("🔍 Поиск"),
+			tgbotapi.NewKeyboardButton(b.i18n.T(lang, "button.users")),
+			tgbotapi.NewKeyboardButton(b.i18n.T(lang, "button.search")),
 		}
 		buttons = append(buttons, adminRow)
 	}
@@ -711,37 +889,11 @@ func (b *Bot) createPermanentMenu(userID int64) tgbotapi.ReplyKeyboardMarkup {
 	return tgbotapi.NewReplyKeyboard(buttons...)
 }
 
-// isMenuButton проверяет, является ли текст кнопкой меню
-func (b *Bot) isMenuButton(text string) bool {
-	menuButtons := []string{
-		"📝 Регистрация",
-		"📊 Статус",
-		"❓ Справка",
-		"🏠 Меню",
-		"👥 Пользователи",
-		"🔍 Поиск",
-	}
-
-	for _, button := range menuButtons {
-		if text == button {
-			return true
-		}
-	}
-	return false
-}
-
 // handleAdminSearchMode обрабатывает включение режима поиска для админа
-func (b *Bot) handleAdminSearchMode(message *tgbotapi.Message) {
-	if message.From.ID != b.config.AdminID {
-		text := "❌ У вас нет прав для выполнения этой команды."
-		msg := tgbotapi.NewMessage(message.Chat.ID, text)
-		b.api.Send(msg)
-		return
-	}
-
-	text := "🔍 Введите запрос для поиска пользователей:\n\nМожно искать по: имени, фамилии, телефону, email, адресу"
-	msg := tgbotapi.NewMessage(message.Chat.ID, text)
-	b.api.Send(msg)
+func (b *Bot) handleAdminSearchMode(req *request) {
+	text := b.i18n.T(req.lang, "search.admin_prompt")
+	msg := tgbotapi.NewMessage(req.chatID, text)
+	b.send(msg)
 }
 
 // ensureMenuSet устанавливает меню для новых пользователей
@@ -753,11 +905,12 @@ func (b *Bot) ensureMenuSet(message *tgbotapi.Message) {
 
 	// Для всех остальных сообщений проверяем, есть ли меню
 	// Если нет - устанавливаем
-	if !b.isMenuButton(message.Text) && !message.IsCommand() {
+	if !b.router.isButton(message.Text) && !message.IsCommand() {
 		// Устанавливаем меню тихо, чтобы не мешать основному функционалу
-		keyboard := b.createPermanentMenu(message.From.ID)
-		msg := tgbotapi.NewMessage(message.Chat.ID, "🔄 Меню обновлено")
+		lang := b.lang(message.From)
+		keyboard := b.createPermanentMenu(lang, message.From.ID)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "menu.updated"))
 		msg.ReplyMarkup = keyboard
-		b.api.Send(msg)
+		b.send(msg)
 	}
 }
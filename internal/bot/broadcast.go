@@ -0,0 +1,348 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+	"telegram_verification_bot/internal/export"
+	"telegram_verification_bot/internal/models"
+)
+
+// defaultBroadcastRate сообщений рассылки в секунду; общее ограничение
+// Telegram — около 30 сообщений в секунду
+const defaultBroadcastRate = 20
+
+// Виды содержимого рассылки
+const (
+	broadcastText     = "text"
+	broadcastPhoto    = "photo"
+	broadcastDocument = "document"
+)
+
+// broadcastContent сообщение рассылки: текст, фото или документ с подписью
+type broadcastContent struct {
+	Kind     string
+	Text     string // текст или подпись
+	Entities []tgbotapi.MessageEntity
+	FileID   string
+}
+
+// contentOf извлекает содержимое рассылки из сообщения администратора
+func contentOf(message *tgbotapi.Message) (*broadcastContent, bool) {
+	switch {
+	case len(message.Photo) > 0:
+		// Последний размер фото самый крупный
+		photo := message.Photo[len(message.Photo)-1]
+		return &broadcastContent{Kind: broadcastPhoto, Text: message.Caption, Entities: message.CaptionEntities, FileID: photo.FileID}, true
+	case message.Document != nil:
+		return &broadcastContent{Kind: broadcastDocument, Text: message.Caption, Entities: message.CaptionEntities, FileID: message.Document.FileID}, true
+	case message.Text != "":
+		return &broadcastContent{Kind: broadcastText, Text: message.Text, Entities: message.Entities}, true
+	}
+	return nil, false
+}
+
+// message собирает сообщение рассылки для чата chatID
+func (c *broadcastContent) message(chatID int64) tgbotapi.Chattable {
+	switch c.Kind {
+	case broadcastPhoto:
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileID(c.FileID))
+		photo.Caption = c.Text
+		photo.CaptionEntities = c.Entities
+		return photo
+	case broadcastDocument:
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FileID(c.FileID))
+		doc.Caption = c.Text
+		doc.CaptionEntities = c.Entities
+		return doc
+	}
+	msg := tgbotapi.NewMessage(chatID, c.Text)
+	msg.Entities = c.Entities
+	return msg
+}
+
+// broadcastDraft рассылка от выбора получателей до отправки
+type broadcastDraft struct {
+	AdminID    int64
+	Filter     export.Filter
+	Content    *broadcastContent
+	Recipients []int64
+	Created    time.Time // начало набора текста рассылки
+}
+
+// broadcastManager хранит рассылки, для которых администратор пишет текст,
+// и рассылки, ожидающие подтверждения
+type broadcastManager struct {
+	mutex     sync.Mutex
+	composing map[int64]*broadcastDraft
+	pending   *pendingStore[*broadcastDraft]
+}
+
+func newBroadcastManager() *broadcastManager {
+	return &broadcastManager{
+		composing: make(map[int64]*broadcastDraft),
+		pending:   newPendingStore[*broadcastDraft](pendingTTL),
+	}
+}
+
+// startCompose запоминает, что следующее сообщение администратора — текст рассылки
+func (m *broadcastManager) startCompose(draft *broadcastDraft) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.composing[draft.AdminID] = draft
+}
+
+// isComposing проверяет, ожидается ли от администратора текст рассылки
+func (m *broadcastManager) isComposing(adminID int64) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	draft, ok := m.composing[adminID]
+	return ok && time.Since(draft.Created) <= pendingTTL
+}
+
+// takeCompose забирает рассылку, для которой ожидался текст
+func (m *broadcastManager) takeCompose(adminID int64) *broadcastDraft {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	draft, ok := m.composing[adminID]
+	delete(m.composing, adminID)
+	if !ok || time.Since(draft.Created) > pendingTTL {
+		return nil
+	}
+	return draft
+}
+
+// broadcastRecipients возвращает ID пользователей, подходящих под фильтр
+func (b *Bot) broadcastRecipients(filter export.Filter) ([]int64, error) {
+	users, err := b.sheets.GetAllUsers(b.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var recipients []int64
+	for _, user := range export.Apply(users, filter) {
+		recipients = append(recipients, user.TelegramID)
+	}
+	return recipients, nil
+}
+
+// handleBroadcast начинает рассылку: разбирает получателей и ждет сообщение.
+// Формат: /broadcast [status=...] [role=...] [settlement=...], /broadcast cancel
+func (b *Bot) handleBroadcast(message *tgbotapi.Message) {
+	args := strings.Fields(message.CommandArguments())
+	lang := b.lang(message.From)
+
+	if len(args) == 1 && strings.EqualFold(args[0], "cancel") {
+		text := b.i18n.T(lang, "broadcast.nothing_to_cancel")
+		if b.broadcasts.takeCompose(message.From.ID) != nil {
+			text = b.i18n.T(lang, "broadcast.cancelled")
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	// Фильтры те же, что у /export, но без формата файла
+	var filter export.Filter
+	var err error
+	for _, arg := range args {
+		if !strings.Contains(arg, "=") {
+			err = errors.New(b.i18n.T(lang, "broadcast.bad_arg", arg))
+			break
+		}
+	}
+	if err == nil {
+		filter, _, err = export.ParseFilter(args)
+	}
+	if err != nil {
+		text := b.i18n.T(lang, "broadcast.usage", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+	// По умолчанию рассылка только верифицированным жителям
+	if filter.Status == "" {
+		filter.Status = models.StatusApproved
+	}
+
+	recipients, err := b.broadcastRecipients(filter)
+	if err != nil {
+		log.Printf("Error loading users for broadcast: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "users.error"))
+		b.send(msg)
+		return
+	}
+	if len(recipients) == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "broadcast.no_recipients"))
+		b.send(msg)
+		return
+	}
+
+	b.broadcasts.startCompose(&broadcastDraft{AdminID: message.From.ID, Filter: filter, Created: time.Now()})
+
+	text := b.i18n.T(lang, "broadcast.compose", b.describeFilter(lang, filter), len(recipients))
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	b.send(msg)
+}
+
+// describeFilter описывает получателей рассылки на языке lang
+func (b *Bot) describeFilter(lang string, filter export.Filter) string {
+	parts := []string{b.i18n.T(lang, "filter.status", filter.Status)}
+	if filter.Role != "" {
+		parts = append(parts, b.i18n.T(lang, "filter.role", b.roleName(lang, filter.Role)))
+	}
+	if filter.Settlement != "" {
+		if filter.SettlementPrefix {
+			parts = append(parts, b.i18n.T(lang, "filter.settlements", filter.Settlement+"*"))
+		} else {
+			parts = append(parts, b.i18n.T(lang, "filter.settlement", filter.Settlement))
+		}
+	}
+	if !filter.From.IsZero() {
+		parts = append(parts, b.i18n.T(lang, "filter.from", filter.From.Format("2006-01-02")))
+	}
+	if !filter.To.IsZero() {
+		parts = append(parts, b.i18n.T(lang, "filter.to", filter.To.Format("2006-01-02")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// handleBroadcastContent принимает сообщение рассылки и показывает предпросмотр
+func (b *Bot) handleBroadcastContent(message *tgbotapi.Message) {
+	lang := b.lang(message.From)
+	content, ok := contentOf(message)
+	if !ok {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "broadcast.bad_content"))
+		b.send(msg)
+		return
+	}
+
+	draft := b.broadcasts.takeCompose(message.From.ID)
+	if draft == nil {
+		return
+	}
+
+	// Получателей пересчитываем: пока писался текст, статусы могли измениться
+	recipients, err := b.broadcastRecipients(draft.Filter)
+	if err != nil {
+		log.Printf("Error loading users for broadcast: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "users.error"))
+		b.send(msg)
+		return
+	}
+	draft.Content = content
+	draft.Recipients = recipients
+
+	if _, err := b.send(content.message(message.Chat.ID)); err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "broadcast.preview_failed", b.sendErrorText(lang, err)))
+		b.send(msg)
+		return
+	}
+
+	token, err := b.broadcasts.pending.put(draft)
+	if err != nil {
+		log.Printf("Error storing broadcast draft: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "broadcast.store_failed"))
+		b.send(msg)
+		return
+	}
+	text := b.i18n.T(lang, "broadcast.preview", b.describeFilter(lang, draft.Filter), len(recipients))
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	if len(recipients) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				b.actionButton(b.i18n.T(lang, "broadcast.send", len(recipients)), "bcsend", 0, token),
+				b.actionButton(b.i18n.T(lang, "button.cancel"), "bccancel", 0, token),
+			),
+		)
+	}
+	b.send(msg)
+}
+
+// handleBroadcastCallback запускает или отменяет рассылку после предпросмотра
+func (b *Bot) handleBroadcastCallback(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	lang := b.lang(callback.From)
+	draft, ok := b.broadcasts.pending.take(payload.Arg(0))
+	if !ok || draft.AdminID != callback.From.ID {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "broadcast.stale"))
+		b.send(msg)
+		return
+	}
+
+	if payload.Action == "bccancel" {
+		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+			callback.Message.Text+b.i18n.T(lang, "action.cancelled"))
+		b.send(editMsg)
+		return
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		callback.Message.Text+b.i18n.T(lang, "broadcast.running"))
+	b.send(editMsg)
+
+	// Рассылка может идти минуты, обработчик администратора не занимаем
+	chatID := callback.Message.Chat.ID
+	b.spawn(func() { b.runBroadcast(b.ctx, chatID, draft) })
+}
+
+// runBroadcast отправляет рассылку не быстрее broadcast_per_second сообщений
+// в секунду и присылает администратору отчет. Заблокировавшим бота не пишет.
+// При остановке бота рассылка прерывается, отчет описывает отправленную часть.
+func (b *Bot) runBroadcast(ctx context.Context, chatID int64, draft *broadcastDraft) {
+	rate := b.config.BroadcastPerSecond
+	if rate <= 0 {
+		rate = defaultBroadcastRate
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	start := time.Now()
+	delivered, blocked, failed := 0, 0, 0
+	processed := 0
+recipients:
+	for _, userID := range draft.Recipients {
+		if b.isBlocked(userID) {
+			blocked++
+			processed++
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			break recipients
+		case <-ticker.C:
+		}
+
+		_, err := b.send(draft.Content.message(userID))
+		processed++
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, errBlocked):
+			blocked++
+		default:
+			failed++
+		}
+	}
+
+	log.Printf("Broadcast to %d of %d recipients finished in %v: delivered %d, blocked %d, failed %d",
+		processed, len(draft.Recipients), time.Since(start).Round(time.Second), delivered, blocked, failed)
+
+	lang := b.langOf(draft.AdminID)
+	header := b.i18n.T(lang, "broadcast.finished", b.describeFilter(lang, draft.Filter))
+	if processed < len(draft.Recipients) {
+		header = b.i18n.T(lang, "broadcast.interrupted",
+			b.describeFilter(lang, draft.Filter), processed, len(draft.Recipients))
+	}
+	text := b.i18n.T(lang, "broadcast.report", header, delivered, blocked, failed)
+	msg := tgbotapi.NewMessage(chatID, text)
+	b.send(msg)
+}
@@ -0,0 +1,313 @@
+package bot
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+	"telegram_verification_bot/internal/models"
+	"telegram_verification_bot/internal/templates"
+)
+
+const (
+	maxBulkSize     = 1 << 20
+	bulkPreviewErrs = 10
+)
+
+// bulkRow строка файла массовой модерации
+type bulkRow struct {
+	Line       int
+	TelegramID int64
+	Status     models.UserStatus
+	Role       models.UserRole
+	Comment    string
+	Err        string        // ключ текста ошибки проверки, строка не применяется
+	ErrArgs    []interface{} // аргументы текста ошибки
+}
+
+// errBulkEmpty в файле массовой модерации нет ни одной строки
+var errBulkEmpty = errors.New("no rows in bulk file")
+
+// bulkErrorText описывает ошибку строки на языке lang
+func (b *Bot) bulkErrorText(lang string, row *bulkRow) string {
+	return strings.TrimPrefix(b.i18n.T(lang, row.Err, row.ErrArgs...), "❌ ")
+}
+
+// bulkBatch проверенный файл, ожидающий подтверждения
+type bulkBatch struct {
+	AdminID int64
+	Rows    []bulkRow
+}
+
+// parseModerationIDs разбирает первый аргумент команды: "1,2,3" -> [1 2 3].
+// Несколько ID пишутся через запятую без пробелов, чтобы число в начале
+// причины отказа не приняли за ID.
+func parseModerationIDs(arg string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(arg, ",") {
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID %q", part)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no user IDs")
+	}
+	return ids, nil
+}
+
+// notifyDecision сообщает пользователю о решении модератора. Пустая причина
+// отказа заменяется на «не указана» на языке пользователя. Ошибку доставки
+// вызывающий показывает модератору.
+func (b *Bot) notifyDecision(userID int64, status models.UserStatus, role models.UserRole, reason string) error {
+	lang := b.langOf(userID)
+	if status == models.StatusRejected && reason == "" {
+		reason = b.i18n.T(lang, "reason.unspecified")
+	}
+
+	data := templates.Data{TelegramID: userID}
+	if user, err := b.getUser(userID); err == nil {
+		data = templates.FromUser(user)
+	}
+	data.Status = string(status)
+	data.Role = b.roleName(lang, role)
+	data.Reason = reason
+
+	var text string
+	switch status {
+	case models.StatusApproved:
+		text = b.renderTemplate(templates.Approved, lang, data, b.i18n.T(lang, "decision.approved", data.Role))
+	case models.StatusRejected:
+		text = b.renderTemplate(templates.Rejected, lang, data, b.i18n.T(lang, "decision.rejected", reason))
+	default:
+		text = b.i18n.T(lang, "decision.pending")
+	}
+	msg := tgbotapi.NewMessage(userID, text)
+	_, err := b.send(msg)
+	return err
+}
+
+// parseBulkCSV читает строки telegram_id,status,role,comment. Строка
+// заголовков необязательна, разделитель — запятая или точка с запятой.
+func parseBulkCSV(data []byte) ([]bulkRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %v", err)
+	}
+
+	var rows []bulkRow
+	seen := make(map[int64]int)
+	for i, record := range records {
+		if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "telegram_id") {
+			continue
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		row := bulkRow{Line: i + 1}
+		row.TelegramID, err = strconv.ParseInt(field(record, 0), 10, 64)
+		row.Status = models.UserStatus(strings.ToLower(field(record, 1)))
+		row.Role = models.UserRole(field(record, 2))
+		row.Comment = field(record, 3)
+
+		switch {
+		case err != nil:
+			row.Err, row.ErrArgs = "bulk.bad_id", []interface{}{field(record, 0)}
+		case !row.Status.Valid():
+			row.Err, row.ErrArgs = "bulk.bad_status", []interface{}{field(record, 1)}
+		case row.Status == models.StatusApproved && (row.Role == "" || row.Role == models.RoleGuest):
+			row.Err = "bulk.role_required"
+		case row.Role != "" && !row.Role.Valid():
+			row.Err, row.ErrArgs = "bulk.bad_role", []interface{}{string(row.Role)}
+		case seen[row.TelegramID] > 0:
+			row.Err, row.ErrArgs = "bulk.duplicate", []interface{}{seen[row.TelegramID]}
+		}
+		if row.Err == "" {
+			seen[row.TelegramID] = row.Line
+		}
+		if row.Status != models.StatusApproved {
+			row.Role = models.RoleGuest
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, errBulkEmpty
+	}
+	return rows, nil
+}
+
+func field(record []string, i int) string {
+	if i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
+
+// handleBulkUpload проверяет файл массовой модерации и показывает сводку
+// с кнопкой подтверждения
+func (b *Bot) handleBulkUpload(message *tgbotapi.Message) {
+	lang := b.lang(message.From)
+	if message.Document == nil {
+		text := b.i18n.T(lang, "bulk.usage")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	data, err := b.downloadDocument(message.Document, maxBulkSize)
+	if err != nil {
+		log.Printf("Error downloading bulk file: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "file.download_failed"))
+		b.send(msg)
+		return
+	}
+
+	rows, err := parseBulkCSV(data)
+	if err != nil {
+		text := b.i18n.T(lang, "file.invalid", err)
+		if errors.Is(err, errBulkEmpty) {
+			text = b.i18n.T(lang, "bulk.empty")
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	counts := make(map[models.UserStatus]int)
+	var errs []string
+	for i := range rows {
+		row := &rows[i]
+		if row.Err == "" {
+			if _, err := b.getUser(row.TelegramID); err != nil {
+				row.Err = statusErrorKey(err)
+			}
+		}
+		if row.Err != "" {
+			errs = append(errs, b.i18n.T(lang, "bulk.row_error", row.Line, b.bulkErrorText(lang, row)))
+			continue
+		}
+		counts[row.Status]++
+	}
+
+	valid := len(rows) - len(errs)
+	text := b.i18n.T(lang, "bulk.summary",
+		len(rows), counts[models.StatusApproved], counts[models.StatusRejected], counts[models.StatusPending], len(errs))
+
+	if len(errs) > 0 {
+		shown := errs
+		if len(shown) > bulkPreviewErrs {
+			shown = shown[:bulkPreviewErrs]
+		}
+		text += "\n\n" + strings.Join(shown, "\n")
+		if len(errs) > len(shown) {
+			text += "\n" + b.i18n.T(lang, "list.more", len(errs)-len(shown))
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	if valid > 0 {
+		token, err := b.bulk.put(&bulkBatch{AdminID: message.From.ID, Rows: rows})
+		if err != nil {
+			log.Printf("Error storing bulk batch: %v", err)
+			msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "bulk.store_failed"))
+			b.send(msg)
+			return
+		}
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				b.actionButton(b.i18n.T(lang, "bulk.apply", valid), "bulk", 0, token),
+				b.actionButton(b.i18n.T(lang, "button.cancel"), "bulkcancel", 0, token),
+			),
+		)
+	}
+	b.send(msg)
+}
+
+// handleBulkCallback применяет или отменяет проверенный файл
+func (b *Bot) handleBulkCallback(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	cancel := payload.Action == "bulkcancel"
+	token := payload.Arg(0)
+	lang := b.lang(callback.From)
+
+	batch, ok := b.bulk.take(token)
+	if !ok || batch.AdminID != callback.From.ID {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "bulk.stale"))
+		b.send(msg)
+		return
+	}
+
+	if cancel {
+		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+			callback.Message.Text+b.i18n.T(lang, "action.cancelled"))
+		b.send(editMsg)
+		return
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		callback.Message.Text+b.i18n.T(lang, "bulk.applying"))
+	b.send(editMsg)
+
+	moderator := moderatorName(callback.From)
+	var report bytes.Buffer
+	writer := csv.NewWriter(&report)
+	writer.Write([]string{"line", "telegram_id", "status", "role", "result"})
+
+	applied, failed, undelivered := 0, 0, 0
+	for i := range batch.Rows {
+		row := &batch.Rows[i]
+		result := "ok"
+		if row.Err != "" {
+			result = b.i18n.T(lang, "bulk.result_skipped", b.bulkErrorText(lang, row))
+			failed++
+		} else if err := b.setUserStatus(row.TelegramID, row.Status, row.Role, row.Comment, moderator); err != nil {
+			result = b.i18n.T(lang, "bulk.result_failed", b.bulkErrorText(lang, &bulkRow{Err: statusErrorKey(err)}))
+			failed++
+		} else {
+			if err := b.notifyDecision(row.TelegramID, row.Status, row.Role, row.Comment); err != nil {
+				result = b.i18n.T(lang, "bulk.result_undelivered", b.sendErrorText(lang, err))
+				undelivered++
+			}
+			applied++
+		}
+		writer.Write([]string{strconv.Itoa(row.Line), strconv.FormatInt(row.TelegramID, 10), string(row.Status), string(row.Role), result})
+	}
+	writer.Flush()
+
+	text := b.i18n.T(lang, "bulk.done", applied, failed)
+	if undelivered > 0 {
+		text += b.i18n.N(lang, "bulk.undelivered", undelivered)
+	}
+	doc := tgbotapi.NewDocument(callback.Message.Chat.ID, tgbotapi.FileBytes{
+		Name:  fmt.Sprintf("bulk_results_%s.csv", time.Now().Format("2006-01-02_15-04")),
+		Bytes: report.Bytes(),
+	})
+	doc.Caption = text
+	if _, err := b.send(doc); err != nil {
+		log.Printf("Error sending bulk report: %v", err)
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+		b.send(msg)
+	}
+}
@@ -0,0 +1,97 @@
+package bot
+
+import (
+	"testing"
+
+	"telegram_verification_bot/internal/models"
+)
+
+func TestParseBulkCSV(t *testing.T) {
+	type want struct {
+		line   int
+		id     int64
+		status models.UserStatus
+		role   models.UserRole
+		ok     bool // строка без ошибки
+	}
+	tests := []struct {
+		name    string
+		data    string
+		want    []want
+		wantErr bool
+	}{
+		{
+			name: "comma with header",
+			data: "telegram_id,status,role,comment\n1,approved,житель,\n2,rejected,,спам\n",
+			want: []want{
+				{2, 1, models.StatusApproved, models.RoleResident, true},
+				{3, 2, models.StatusRejected, models.RoleGuest, true},
+			},
+		},
+		{
+			name: "semicolon without header",
+			data: "1;Approved;сосед;ок, проверен\n2;pending;;\n",
+			want: []want{
+				{1, 1, models.StatusApproved, models.RoleNeighbor, true},
+				{2, 2, models.StatusPending, models.RoleGuest, true},
+			},
+		},
+		{
+			name: "bom and header in other case",
+			data: "\ufeffTelegram_ID,status\n3,rejected\n",
+			want: []want{
+				{2, 3, models.StatusRejected, models.RoleGuest, true},
+			},
+		},
+		{
+			name: "duplicate id keeps first",
+			data: "1,approved,житель\n1,rejected\n2,rejected\n",
+			want: []want{
+				{1, 1, models.StatusApproved, models.RoleResident, true},
+				{2, 1, models.StatusRejected, models.RoleGuest, false},
+				{3, 2, models.StatusRejected, models.RoleGuest, true},
+			},
+		},
+		{
+			name: "invalid rows do not block duplicates",
+			data: "1,approved\n1,approved,житель\n",
+			want: []want{
+				{1, 1, models.StatusApproved, "", false},
+				{2, 1, models.StatusApproved, models.RoleResident, true},
+			},
+		},
+		{
+			name: "row errors",
+			data: "abc,approved,житель\n4,banned\n5,approved,гость\n6,approved,админ\n",
+			want: []want{
+				{1, 0, models.StatusApproved, models.RoleResident, false},
+				{2, 4, "banned", models.RoleGuest, false},
+				{3, 5, models.StatusApproved, models.RoleGuest, false},
+				{4, 6, models.StatusApproved, "админ", false},
+			},
+		},
+		{name: "header only", data: "telegram_id,status,role,comment\n", wantErr: true},
+		{name: "broken quotes", data: "1,\"approved\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseBulkCSV([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBulkCSV: err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(rows) != len(tt.want) {
+				t.Fatalf("got %d rows, want %d: %+v", len(rows), len(tt.want), rows)
+			}
+			for i, w := range tt.want {
+				row := rows[i]
+				if row.Line != w.line || row.TelegramID != w.id || row.Status != w.status || row.Role != w.role {
+					t.Errorf("row %d = %+v, want %+v", i, row, w)
+				}
+				if (row.Err == "") != w.ok {
+					t.Errorf("row %d: Err = %q, want ok %v", i, row.Err, w.ok)
+				}
+			}
+		})
+	}
+}
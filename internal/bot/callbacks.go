@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+	"telegram_verification_bot/internal/config"
+)
+
+// staleButtonData данные кнопки, которую не удалось закодировать; при
+// нажатии она обрабатывается как устаревшая
+const staleButtonData = "-"
+
+// newCallbackCodec создает кодек данных кнопок. Без callback_secret ключ
+// выводится из токена бота, чтобы кнопки переживали перезапуск.
+func newCallbackCodec(cfg *config.Config) *callbackdata.Codec {
+	secret := cfg.CallbackSecret
+	if secret == "" {
+		sum := sha256.Sum256([]byte("callback:" + cfg.TelegramToken))
+		secret = hex.EncodeToString(sum[:])
+	}
+	return callbackdata.New(secret, time.Duration(cfg.CallbackTTLHours)*time.Hour)
+}
+
+// actionButton создает inline кнопку действия action для пользователя target
+func (b *Bot) actionButton(label, action string, target int64, args ...string) tgbotapi.InlineKeyboardButton {
+	data, err := b.codec.Encode(action, target, args...)
+	if err != nil {
+		log.Printf("Error encoding %s button: %v", action, err)
+		data = staleButtonData
+	}
+	return tgbotapi.NewInlineKeyboardButtonData(label, data)
+}
+
+// handleStaleCallback убирает устаревшие или поддельные кнопки и объясняет,
+// что делать дальше
+func (b *Bot) handleStaleCallback(req *request) {
+	// Истекшие кнопки и кнопки старого формата — обычное дело, остальное стоит заметить
+	switch {
+	case errors.Is(req.err, callbackdata.ErrExpired),
+		errors.Is(req.err, callbackdata.ErrVersion),
+		errors.Is(req.err, callbackdata.ErrMalformed):
+	default:
+		log.Printf("Rejected callback from %d: %v", req.from.ID, req.err)
+	}
+
+	message := req.callback.Message
+	if message != nil {
+		edit := tgbotapi.NewEditMessageReplyMarkup(message.Chat.ID, message.MessageID,
+			tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+		b.api.Request(edit)
+	}
+
+	b.reply(req, b.i18n.T(req.lang, "error.stale_button"))
+}
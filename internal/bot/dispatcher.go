@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	defaultWorkers   = 8
+	defaultQueueSize = 100
+)
+
+// dispatcher распределяет обновления по ограниченному числу обработчиков.
+// Обновления одного пользователя всегда попадают к одному обработчику и
+// выполняются по порядку. Если очередь обработчика заполнена, прием
+// обновлений ждет, пока она освободится.
+type dispatcher struct {
+	queues []chan func()
+	wg     sync.WaitGroup
+}
+
+func newDispatcher(workers, queueSize int) *dispatcher {
+	if workers <= 0 {
+		workers = defaultWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultQueueSize
+	}
+
+	d := &dispatcher{queues: make([]chan func(), workers)}
+	for i := range d.queues {
+		queue := make(chan func(), queueSize)
+		d.queues[i] = queue
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for job := range queue {
+				job()
+			}
+		}()
+	}
+	return d
+}
+
+// submit ставит задачу в очередь обработчика пользователя
+func (d *dispatcher) submit(key int64, job func()) {
+	d.queues[uint64(key)%uint64(len(d.queues))] <- job
+}
+
+// depth возвращает число задач в очередях, всего и по обработчикам
+func (d *dispatcher) depth() (int, []int) {
+	total := 0
+	perWorker := make([]int, len(d.queues))
+	for i, queue := range d.queues {
+		perWorker[i] = len(queue)
+		total += perWorker[i]
+	}
+	return total, perWorker
+}
+
+// capacity возвращает суммарный размер очередей
+func (d *dispatcher) capacity() int {
+	return len(d.queues) * cap(d.queues[0])
+}
+
+// close закрывает очереди: обработчики доделают поставленные задачи и завершатся
+func (d *dispatcher) close() {
+	for _, queue := range d.queues {
+		close(queue)
+	}
+}
+
+// wait ждет завершения обработчиков после close
+func (d *dispatcher) wait() {
+	d.wg.Wait()
+}
+
+// updateKey возвращает пользователя, по которому упорядочиваются обновления
+func updateKey(update tgbotapi.Update) int64 {
+	if user := update.SentFrom(); user != nil {
+		return user.ID
+	}
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID
+	}
+	return 0
+}
+
+// handleQueue показывает админу заполненность очередей обработчиков
+func (b *Bot) handleQueue(message *tgbotapi.Message) {
+	total, perWorker := b.dispatcher.depth()
+	depths := make([]string, len(perWorker))
+	for i, depth := range perWorker {
+		depths[i] = fmt.Sprint(depth)
+	}
+
+	text := b.i18n.T(b.lang(message.From), "queue.depth",
+		total, b.dispatcher.capacity(), strings.Join(depths, " "))
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	b.send(msg)
+}
@@ -0,0 +1,213 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/models"
+	"telegram_verification_bot/internal/sheets"
+)
+
+const maxDocuments = 5
+
+// documentFromMessage извлекает фото или PDF из сообщения
+func documentFromMessage(message *tgbotapi.Message) (*models.Document, bool) {
+	doc := &models.Document{
+		ApplicantID: message.From.ID,
+		UploadedAt:  time.Now(),
+	}
+
+	switch {
+	case len(message.Photo) > 0:
+		// Telegram присылает несколько размеров, последний — самый крупный
+		photo := message.Photo[len(message.Photo)-1]
+		doc.FileID = photo.FileID
+		doc.Kind = models.DocumentPhoto
+		doc.MimeType = "image/jpeg"
+		doc.Size = photo.FileSize
+	case message.Document != nil:
+		mime := message.Document.MimeType
+		if mime != "application/pdf" && !strings.HasPrefix(mime, "image/") {
+			return nil, false
+		}
+		doc.FileID = message.Document.FileID
+		doc.Kind = models.DocumentFile
+		doc.FileName = message.Document.FileName
+		doc.MimeType = mime
+		doc.Size = message.Document.FileSize
+	default:
+		return nil, false
+	}
+	return doc, true
+}
+
+// handleDocumentStep принимает документы на шаге регистрации.
+// Возвращает true, когда шаг завершен.
+func (b *Bot) handleDocumentStep(message *tgbotapi.Message, reg *models.RegistrationState) bool {
+	lang := b.lang(message.From)
+
+	if doc, ok := documentFromMessage(message); ok {
+		// Альбом приходит отдельными сообщениями, они обрабатываются по очереди
+		full := len(reg.Documents) >= maxDocuments
+		if !full {
+			reg.Documents = append(reg.Documents, *doc)
+		}
+		count := len(reg.Documents)
+
+		text := b.i18n.T(lang, "documents.received", count, maxDocuments)
+		if full {
+			text = b.i18n.N(lang, "documents.limit", maxDocuments)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return false
+	}
+
+	if message.Document != nil {
+		text := b.i18n.T(lang, "documents.unsupported")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return false
+	}
+
+	// «Готово» принимается на любом языке
+	answer := strings.ToLower(strings.TrimSpace(message.Text))
+	if answer == "-" {
+		return true
+	}
+	for _, l := range b.i18n.Languages() {
+		if answer == b.i18n.T(l, "documents.done") {
+			return true
+		}
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.N(lang, "documents.prompt", maxDocuments))
+	b.send(msg)
+	return false
+}
+
+// forwardDocuments отправляет документы заявителя модератору и запоминает
+// их вместе с номерами сообщений, чтобы удалить после решения
+func (b *Bot) forwardDocuments(user *models.User, docs []models.Document) {
+	lang := b.langOf(b.config.AdminID)
+	for i := range docs {
+		doc := docs[i]
+		caption := b.i18n.T(lang, "documents.caption",
+			i+1, len(docs), user.FirstName, user.LastName, user.TelegramID)
+
+		var sent tgbotapi.Message
+		var err error
+		if doc.Kind == models.DocumentPhoto {
+			photo := tgbotapi.NewPhoto(b.config.AdminID, tgbotapi.FileID(doc.FileID))
+			photo.Caption = caption
+			sent, err = b.send(photo)
+		} else {
+			file := tgbotapi.NewDocument(b.config.AdminID, tgbotapi.FileID(doc.FileID))
+			file.Caption = caption
+			sent, err = b.send(file)
+		}
+		if err != nil {
+			log.Printf("Error forwarding document: %v", err)
+		} else {
+			doc.AdminMessageID = sent.MessageID
+		}
+
+		if err := b.sheets.AddDocument(b.ctx, &doc); err != nil {
+			log.Printf("Error saving document: %v", err)
+		}
+	}
+}
+
+// purgeDocuments удаляет документы заявителя и их копии у модератора
+func (b *Bot) purgeDocuments(applicantID int64) {
+	docs, err := b.sheets.GetDocuments(b.ctx)
+	if err != nil {
+		log.Printf("Error loading documents: %v", err)
+		return
+	}
+
+	found := false
+	for _, doc := range docs {
+		if doc.ApplicantID != applicantID {
+			continue
+		}
+		found = true
+		if doc.AdminMessageID != 0 {
+			// Telegram не дает удалять сообщения старше 48 часов, это не ошибка очистки
+			b.api.Request(tgbotapi.NewDeleteMessage(b.config.AdminID, doc.AdminMessageID))
+		}
+	}
+	if !found {
+		return
+	}
+
+	count, err := b.sheets.DeleteDocuments(b.ctx, applicantID)
+	if err != nil {
+		log.Printf("Error purging documents: %v", err)
+		return
+	}
+	log.Printf("Purged %d documents of user %d", count, applicantID)
+}
+
+// purgeDecidedDocuments удаляет документы по заявкам, по которым уже принято
+// решение, в том числе вручную в таблице или когда очистка не удалась сразу
+func (b *Bot) purgeDecidedDocuments() {
+	docs, err := b.sheets.GetDocuments(b.ctx)
+	if err != nil {
+		log.Printf("Error loading documents: %v", err)
+		return
+	}
+
+	checked := make(map[int64]bool)
+	for _, doc := range docs {
+		if checked[doc.ApplicantID] {
+			continue
+		}
+		checked[doc.ApplicantID] = true
+
+		user, err := b.getUser(doc.ApplicantID)
+		if errors.Is(err, sheets.ErrUserNotFound) || (err == nil && user.Status != models.StatusPending) {
+			b.purgeDocuments(doc.ApplicantID)
+		}
+	}
+}
+
+// captionCommand возвращает команду из подписи к документу: "/bulk" -> "bulk"
+func captionCommand(message *tgbotapi.Message) string {
+	if message.Document == nil || !strings.HasPrefix(message.Caption, "/") {
+		return ""
+	}
+	command := strings.Fields(message.Caption)[0][1:]
+	command, _, _ = strings.Cut(command, "@")
+	return command
+}
+
+// downloadDocument скачивает присланный файл не больше limit байт
+func (b *Bot) downloadDocument(doc *tgbotapi.Document, limit int) ([]byte, error) {
+	if doc.FileSize > limit {
+		return nil, fmt.Errorf("file is too large: %d bytes", doc.FileSize)
+	}
+
+	url, err := b.api.GetFileDirectURL(doc.FileID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, int64(limit)))
+}
@@ -0,0 +1,75 @@
+package bot
+
+import (
+	"log"
+	"strings"
+
+	"telegram_verification_bot/internal/models"
+)
+
+// duplicateMatch существующая запись, похожая на новую заявку
+type duplicateMatch struct {
+	User    *models.User
+	Reasons []string // ключи текстов, по которым совпала запись
+}
+
+// findDuplicates ищет среди других аккаунтов записи с тем же телефоном,
+// email или сочетанием имени и адреса
+func (b *Bot) findDuplicates(user *models.User) []duplicateMatch {
+	users, err := b.sheets.GetAllUsers(b.ctx)
+	if err != nil {
+		log.Printf("Error loading users for duplicate check: %v", err)
+		return nil
+	}
+
+	phone := models.NormalizePhone(user.Phone)
+	email := models.NormalizeEmail(user.Email)
+	nameAddress := models.NormalizeName(user.FirstName+" "+user.LastName) + "|" + models.NormalizeAddress(user.Address)
+
+	var matches []duplicateMatch
+	for _, other := range users {
+		if other.TelegramID == user.TelegramID {
+			continue
+		}
+
+		var reasons []string
+		if phone != "" && models.NormalizePhone(other.Phone) == phone {
+			reasons = append(reasons, "duplicates.phone")
+		}
+		if email != "" && models.NormalizeEmail(other.Email) == email {
+			reasons = append(reasons, "duplicates.email")
+		}
+		otherNameAddress := models.NormalizeName(other.FirstName+" "+other.LastName) + "|" + models.NormalizeAddress(other.Address)
+		if !strings.HasPrefix(nameAddress, "|") && !strings.HasSuffix(nameAddress, "|") && otherNameAddress == nameAddress {
+			reasons = append(reasons, "duplicates.name_address")
+		}
+
+		if len(reasons) > 0 {
+			matches = append(matches, duplicateMatch{User: other, Reasons: reasons})
+		}
+	}
+
+	return matches
+}
+
+// formatDuplicates формирует блок предупреждения для карточки модератора
+func (b *Bot) formatDuplicates(lang string, matches []duplicateMatch) string {
+	if len(matches) == 0 {
+		return ""
+	}
+
+	text := b.i18n.T(lang, "duplicates.header")
+	for _, match := range matches {
+		reasons := make([]string, len(match.Reasons))
+		for i, key := range match.Reasons {
+			reasons[i] = b.i18n.T(lang, key)
+		}
+		text += b.i18n.T(lang, "duplicates.item",
+			match.User.FirstName, match.User.LastName, match.User.Username,
+			match.User.TelegramID, match.User.Status, strings.Join(reasons, ", "))
+		if url, ok := b.sheets.RowURL(b.ctx, match.User.TelegramID); ok {
+			text += "\n  " + url
+		}
+	}
+	return text
+}
@@ -0,0 +1,63 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/config"
+	"telegram_verification_bot/internal/export"
+)
+
+// handleExport отправляет выгрузку пользователей документом.
+// Формат: /export [csv|xlsx] [status=...] [role=...] [settlement=...] [from=ГГГГ-ММ-ДД] [to=ГГГГ-ММ-ДД]
+func (b *Bot) handleExport(message *tgbotapi.Message) {
+	lang := b.lang(message.From)
+	access, ok := b.config.Access(message.From.ID)
+	if !ok {
+		text := b.i18n.T(lang, "error.no_rights")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	filter, format, err := export.ParseFilter(strings.Fields(message.CommandArguments()))
+	if err != nil {
+		text := b.i18n.T(lang, "export.usage", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	users, err := b.sheets.GetAllUsers(b.ctx)
+	if err != nil {
+		log.Printf("Error loading users for export: %v", err)
+		text := b.i18n.T(lang, "users.error")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+	users = export.Apply(users, filter)
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, users, export.Fields(access == config.AccessFull)); err != nil {
+		log.Printf("Error writing export: %v", err)
+		text := b.i18n.T(lang, "export.failed")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	name := fmt.Sprintf("users_%s.%s", time.Now().Format("2006-01-02"), format)
+	doc := tgbotapi.NewDocument(message.Chat.ID, tgbotapi.FileBytes{Name: name, Bytes: buf.Bytes()})
+	doc.Caption = b.i18n.T(lang, "export.caption", len(users))
+	if access != config.AccessFull {
+		doc.Caption += b.i18n.T(lang, "export.personal_hidden")
+	}
+	if _, err := b.send(doc); err != nil {
+		log.Printf("Error sending export: %v", err)
+	}
+}
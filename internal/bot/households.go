@@ -0,0 +1,188 @@
+package bot
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+	"telegram_verification_bot/internal/models"
+)
+
+// handleSetOwner назначает одобренного жителя основным владельцем его участка.
+// Формат: /owner ID
+func (b *Bot) handleSetOwner(message *tgbotapi.Message) {
+	lang := b.lang(message.From)
+	userID, err := strconv.ParseInt(strings.TrimSpace(message.CommandArguments()), 10, 64)
+	if err != nil {
+		text := b.i18n.T(lang, "owner.usage")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	user, err := b.getUser(userID)
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.statusErrorText(lang, err))
+		b.send(msg)
+		return
+	}
+	if user.Status != models.StatusApproved {
+		text := b.i18n.T(lang, "owner.not_approved")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	plot := models.NormalizeAddress(user.Address)
+	if plot == "" {
+		text := b.i18n.T(lang, "owner.no_address")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	if err := b.sheets.SetHouseholdOwner(b.ctx, plot, userID, moderatorName(message.From)); err != nil {
+		log.Printf("Error setting household owner: %v", err)
+		text := b.i18n.T(lang, "owner.save_failed")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	text := b.i18n.T(lang, "owner.assigned", user.FirstName, user.LastName, plot)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	b.send(msg)
+}
+
+// householdOwner возвращает одобренного основного владельца участка заявителя
+func (b *Bot) householdOwner(user *models.User) *models.User {
+	plot := models.NormalizeAddress(user.Address)
+	if plot == "" {
+		return nil
+	}
+
+	owners, err := b.sheets.GetHouseholdOwners(b.ctx)
+	if err != nil {
+		log.Printf("Error loading household owners: %v", err)
+		return nil
+	}
+
+	ownerID, ok := owners[plot]
+	if !ok || ownerID == user.TelegramID {
+		return nil
+	}
+
+	owner, err := b.getUser(ownerID)
+	if err != nil || owner.Status != models.StatusApproved {
+		return nil
+	}
+	return owner
+}
+
+// formatHousehold формирует блок о домохозяйстве для карточки модератора
+func (b *Bot) formatHousehold(lang string, user *models.User) string {
+	plot := models.NormalizeAddress(user.Address)
+	if plot == "" {
+		return ""
+	}
+
+	users, err := b.sheets.GetAllUsers(b.ctx)
+	if err != nil {
+		return ""
+	}
+
+	residents := 0
+	for _, other := range users {
+		if other.TelegramID != user.TelegramID && other.Status == models.StatusApproved &&
+			models.NormalizeAddress(other.Address) == plot {
+			residents++
+		}
+	}
+
+	text := b.i18n.T(lang, "household.card", plot, residents)
+	if owner := b.householdOwner(user); owner != nil {
+		text += b.i18n.T(lang, "household.card_owner", owner.FirstName, owner.LastName)
+	}
+	return text
+}
+
+// requestOwnerVouch просит основного владельца участка подтвердить,
+// что заявитель проживает с ним
+func (b *Bot) requestOwnerVouch(user *models.User) {
+	owner := b.householdOwner(user)
+	if owner == nil {
+		return
+	}
+
+	lang := b.langOf(owner.TelegramID)
+	text := b.i18n.T(lang, "vouch.household_request",
+		models.NormalizeAddress(user.Address), user.FirstName, user.LastName, user.Username)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			b.actionButton(b.i18n.T(lang, "vouch.confirm"), "hhvouch", user.TelegramID, "yes"),
+			b.actionButton(b.i18n.T(lang, "vouch.deny"), "hhvouch", user.TelegramID, "no"),
+		),
+	)
+
+	msg := tgbotapi.NewMessage(owner.TelegramID, text)
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+}
+
+// handleOwnerVouch обрабатывает ответ владельца участка
+func (b *Bot) handleOwnerVouch(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	applicantID := payload.Target
+	lang := b.lang(callback.From)
+	status := models.VouchDenied
+	if payload.Arg(0) == "yes" {
+		status = models.VouchConfirmed
+	}
+
+	applicant, err := b.getUser(applicantID)
+	if err != nil {
+		return
+	}
+
+	// Отвечать может только текущий владелец участка заявителя
+	owner := b.householdOwner(applicant)
+	if owner == nil || owner.TelegramID != callback.From.ID {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "error.button_outdated"))
+		b.send(msg)
+		return
+	}
+
+	err = b.sheets.AddVouch(b.ctx, &models.Vouch{
+		ApplicantID: applicantID,
+		VoucherID:   owner.TelegramID,
+		Kind:        models.VouchOwner,
+		Status:      status,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		log.Printf("Error saving owner vouch: %v", err)
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "error.generic"))
+		b.send(msg)
+		return
+	}
+
+	answer := b.i18n.T(lang, "vouch.thanks_confirmed")
+	verdict := "household.owner_confirmed"
+	if status != models.VouchConfirmed {
+		answer = b.i18n.T(lang, "vouch.thanks_denied")
+		verdict = "household.owner_denied"
+	}
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, answer)
+	b.send(editMsg)
+
+	text := b.i18n.T(b.langOf(b.config.AdminID), verdict,
+		models.NormalizeAddress(applicant.Address), owner.FirstName, owner.LastName,
+		applicant.FirstName, applicant.LastName, applicantID)
+	adminMsg := tgbotapi.NewMessage(b.config.AdminID, text)
+	b.send(adminMsg)
+
+	b.evaluateVouches(applicant)
+}
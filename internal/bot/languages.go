@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"log"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+	"telegram_verification_bot/internal/i18n"
+	"telegram_verification_bot/internal/models"
+)
+
+// languageStore хранит язык интерфейса пользователей: выбранный командой
+// /language и язык приложения Telegram из последнего обновления
+type languageStore struct {
+	mutex    sync.Mutex
+	chosen   map[int64]string
+	detected map[int64]string
+}
+
+func newLanguageStore() *languageStore {
+	return &languageStore{
+		chosen:   make(map[int64]string),
+		detected: make(map[int64]string),
+	}
+}
+
+// loadLanguages загружает выбранные пользователями языки из таблицы
+func (b *Bot) loadLanguages() error {
+	languages, err := b.sheets.GetLanguages(b.ctx)
+	if err != nil {
+		return err
+	}
+
+	b.languages.mutex.Lock()
+	defer b.languages.mutex.Unlock()
+	for userID, lang := range languages {
+		b.languages.chosen[userID] = b.i18n.Resolve(lang)
+	}
+	return nil
+}
+
+// lang возвращает язык отправителя обновления и запоминает язык его приложения
+func (b *Bot) lang(user *tgbotapi.User) string {
+	b.languages.mutex.Lock()
+	defer b.languages.mutex.Unlock()
+
+	if user.LanguageCode != "" {
+		b.languages.detected[user.ID] = b.i18n.Resolve(user.LanguageCode)
+	}
+	if lang, ok := b.languages.chosen[user.ID]; ok {
+		return lang
+	}
+	if lang, ok := b.languages.detected[user.ID]; ok {
+		return lang
+	}
+	return i18n.DefaultLanguage
+}
+
+// langOf возвращает язык получателя сообщения, которое бот отправляет сам
+func (b *Bot) langOf(userID int64) string {
+	b.languages.mutex.Lock()
+	defer b.languages.mutex.Unlock()
+
+	if lang, ok := b.languages.chosen[userID]; ok {
+		return lang
+	}
+	if lang, ok := b.languages.detected[userID]; ok {
+		return lang
+	}
+	return i18n.DefaultLanguage
+}
+
+// roleName возвращает название роли на языке lang
+func (b *Bot) roleName(lang string, role models.UserRole) string {
+	switch role {
+	case models.RoleGuest:
+		return b.i18n.T(lang, "role.guest")
+	case models.RoleResident:
+		return b.i18n.T(lang, "role.resident")
+	case models.RoleNeighbor:
+		return b.i18n.T(lang, "role.neighbor")
+	case models.RoleOK:
+		return b.i18n.T(lang, "role.ok")
+	}
+	return string(role)
+}
+
+// handleLanguage предлагает выбрать язык интерфейса
+func (b *Bot) handleLanguage(req *request) {
+	var row []tgbotapi.InlineKeyboardButton
+	for _, lang := range b.i18n.Languages() {
+		row = append(row, b.actionButton(b.i18n.T(lang, "language.name"), "language", 0, lang))
+	}
+
+	msg := tgbotapi.NewMessage(req.chatID, b.i18n.T(req.lang, "language.choose"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(row)
+	b.send(msg)
+}
+
+// handleLanguageChoice сохраняет выбранный язык и обновляет меню
+func (b *Bot) handleLanguageChoice(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	lang := b.i18n.Resolve(payload.Arg(0))
+
+	b.languages.mutex.Lock()
+	b.languages.chosen[callback.From.ID] = lang
+	b.languages.mutex.Unlock()
+
+	if err := b.sheets.SetLanguage(b.ctx, callback.From.ID, lang); err != nil {
+		log.Printf("Error saving language: %v", err)
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		b.i18n.T(lang, "language.changed"))
+	b.send(editMsg)
+
+	// Кнопки постоянного меню тоже переводятся
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "menu.updated"))
+	msg.ReplyMarkup = b.createPermanentMenu(lang, callback.From.ID)
+	b.send(msg)
+}
@@ -0,0 +1,148 @@
+package bot
+
+import (
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	defaultRateLimit  = 30
+	defaultRateWindow = time.Minute
+)
+
+// reply отправляет текст в чат запроса
+func (b *Bot) reply(req *request, text string) {
+	if req.chatID == 0 {
+		return
+	}
+	msg := tgbotapi.NewMessage(req.chatID, text)
+	b.send(msg)
+}
+
+// recoverPanics не дает панике в обработчике остановить обработчик очереди
+func (b *Bot) recoverPanics(next handlerFunc) handlerFunc {
+	return func(req *request) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("Panic in %s from %d: %v\n%s", req.route, req.from.ID, r, debug.Stack())
+				b.reply(req, b.i18n.T(req.lang, "error.generic"))
+			}
+		}()
+		next(req)
+	}
+}
+
+// logRequests пишет в журнал маршрут, отправителя и время обработки
+func (b *Bot) logRequests(next handlerFunc) handlerFunc {
+	return func(req *request) {
+		start := time.Now()
+		next(req)
+		log.Printf("%s from %d handled in %v", req.route, req.from.ID, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// rateLimit ограничивает число запросов от одного пользователя. Модераторы
+// не ограничиваются, о превышении пользователь узнает один раз за окно.
+func (b *Bot) rateLimit(next handlerFunc) handlerFunc {
+	return func(req *request) {
+		if _, ok := b.config.Access(req.from.ID); ok {
+			next(req)
+			return
+		}
+
+		allowed, notify := b.limiter.allow(req.from.ID)
+		if !allowed {
+			if notify {
+				b.reply(req, b.i18n.T(req.lang, "error.rate_limited"))
+			}
+			return
+		}
+		next(req)
+	}
+}
+
+// adminOnly пропускает только администратора. На сообщения остальных
+// отвечает отказом, нажатия кнопок молча игнорирует.
+func (b *Bot) adminOnly(next handlerFunc) handlerFunc {
+	return func(req *request) {
+		if req.from.ID != b.config.AdminID {
+			if req.message != nil {
+				b.reply(req, b.i18n.T(req.lang, "error.no_rights"))
+			}
+			return
+		}
+		next(req)
+	}
+}
+
+// rateLimiter считает запросы пользователей в скользящем окне
+type rateLimiter struct {
+	mutex     sync.Mutex
+	history   map[int64][]time.Time
+	notified  map[int64]bool
+	limit     int
+	window    time.Duration
+	lastSweep time.Time
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	if limit <= 0 {
+		limit = defaultRateLimit
+	}
+	if window <= 0 {
+		window = defaultRateWindow
+	}
+
+	return &rateLimiter{
+		history:  make(map[int64][]time.Time),
+		notified: make(map[int64]bool),
+		limit:    limit,
+		window:   window,
+	}
+}
+
+// allow учитывает запрос и сообщает, можно ли его обработать и нужно ли
+// предупредить пользователя о превышении
+func (l *rateLimiter) allow(userID int64) (allowed, notify bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= l.window {
+		l.sweep(now)
+	}
+
+	var recent []time.Time
+	for _, t := range l.history[userID] {
+		if now.Sub(t) < l.window {
+			recent = append(recent, t)
+		}
+	}
+
+	if len(recent) >= l.limit {
+		l.history[userID] = recent
+		notify = !l.notified[userID]
+		l.notified[userID] = true
+		return false, notify
+	}
+
+	l.history[userID] = append(recent, now)
+	delete(l.notified, userID)
+	return true, false
+}
+
+// sweep забывает пользователей, которые не писали дольше окна, чтобы
+// история не росла с каждым новым пользователем
+func (l *rateLimiter) sweep(now time.Time) {
+	for userID, times := range l.history {
+		if len(times) == 0 || now.Sub(times[len(times)-1]) >= l.window {
+			delete(l.history, userID)
+			delete(l.notified, userID)
+		}
+	}
+	l.lastSweep = now
+}
@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/models"
+	"telegram_verification_bot/internal/outbox"
+	"telegram_verification_bot/internal/sheets"
+)
+
+const defaultOutboxPath = "./data/outbox.json"
+
+// getUser возвращает пользователя с учетом записей, еще не отправленных в таблицу
+func (b *Bot) getUser(telegramID int64) (*models.User, error) {
+	user, err := b.sheets.GetUser(b.ctx, telegramID)
+	if err != nil && !errors.Is(err, sheets.ErrUserNotFound) {
+		// Таблица недоступна: отвечаем хотя бы по локальному журналу
+		if pending := b.outbox.PendingUser(telegramID, nil); pending != nil {
+			return pending, nil
+		}
+		return nil, err
+	}
+
+	if pending := b.outbox.PendingUser(telegramID, user); pending != nil {
+		return pending, nil
+	}
+	return nil, sheets.ErrUserNotFound
+}
+
+// setUserStatus записывает решение модератора в журнал, откуда оно
+// будет отправлено в таблицу фоновым обработчиком
+func (b *Bot) setUserStatus(telegramID int64, status models.UserStatus, role models.UserRole, comment, updatedBy string) error {
+	if _, err := b.getUser(telegramID); err != nil {
+		return err
+	}
+
+	err := b.outbox.Enqueue(&outbox.Item{
+		Kind:       outbox.KindUpdateStatus,
+		TelegramID: telegramID,
+		Status:     status,
+		Role:       role,
+		Comment:    comment,
+		UpdatedBy:  updatedBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	// После решения документы заявителя больше не нужны
+	if status != models.StatusPending {
+		b.spawn(func() { b.purgeDocuments(telegramID) })
+	}
+	return nil
+}
+
+// statusErrorText формирует ответ модератору, если решение не удалось сохранить
+func (b *Bot) statusErrorText(lang string, err error) string {
+	return b.i18n.T(lang, statusErrorKey(err))
+}
+
+// statusErrorKey возвращает ключ текста ошибки сохранения решения
+func statusErrorKey(err error) string {
+	if errors.Is(err, sheets.ErrUserNotFound) {
+		return "moderation.user_not_found"
+	}
+	return "moderation.update_failed"
+}
+
+// applyOutboxItem отправляет запись из журнала в Google Sheets
+func (b *Bot) applyOutboxItem(item *outbox.Item) error {
+	switch item.Kind {
+	case outbox.KindAddUser:
+		// Строка могла быть записана до сбоя, не дублируем ее
+		if _, err := b.sheets.GetUser(b.ctx, item.TelegramID); err == nil {
+			return nil
+		}
+		return b.sheets.AddUser(b.ctx, item.User)
+	case outbox.KindUpdateStatus:
+		return b.sheets.UpdateUserStatus(b.ctx, item.TelegramID, item.Status, item.Role, item.Comment, item.UpdatedBy)
+	}
+	return fmt.Errorf("unknown outbox item kind %q", item.Kind)
+}
+
+// handleOutbox показывает админу записи, ожидающие отправки в таблицу.
+// "/outbox drop N" удаляет запись без отправки.
+func (b *Bot) handleOutbox(message *tgbotapi.Message) {
+	lang := b.lang(message.From)
+	args := strings.Fields(message.CommandArguments())
+	if len(args) == 2 && args[0] == "drop" {
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "outbox.bad_id"))
+			b.send(msg)
+			return
+		}
+
+		text := b.i18n.T(lang, "outbox.dropped", id)
+		dropped, err := b.outbox.Drop(id)
+		if err != nil {
+			text = b.i18n.T(lang, "outbox.save_failed")
+		} else if !dropped {
+			text = b.i18n.T(lang, "outbox.not_found", id)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	items := b.outbox.Items()
+	if len(items) == 0 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "outbox.empty"))
+		b.send(msg)
+		return
+	}
+
+	text := b.i18n.T(lang, "outbox.header", len(items))
+	for _, item := range items {
+		mark := "⏳"
+		if item.Stuck() {
+			mark = "⚠️"
+		}
+
+		var what string
+		switch item.Kind {
+		case outbox.KindAddUser:
+			what = b.i18n.T(lang, "outbox.kind_add")
+		case outbox.KindUpdateStatus:
+			what = b.i18n.T(lang, "outbox.kind_status", item.Status, b.roleName(lang, item.Role))
+		default:
+			what = string(item.Kind)
+		}
+
+		text += b.i18n.T(lang, "outbox.item",
+			mark, item.ID, item.TelegramID, what,
+			item.CreatedAt.Format("2006-01-02 15:04:05"), item.Attempts)
+		if item.LastError != "" {
+			text += b.i18n.T(lang, "outbox.item_error", item.LastError)
+		}
+		text += "\n"
+
+		// Telegram ограничивает размер сообщения
+		if len(text) > 3500 {
+			msg := tgbotapi.NewMessage(message.Chat.ID, text)
+			b.send(msg)
+			text = ""
+		}
+	}
+	text += b.i18n.T(lang, "outbox.drop_hint")
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	b.send(msg)
+}
@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// pendingTTL сколько файл массовой модерации, шаблон или рассылка ждут
+// нажатия кнопки подтверждения
+const pendingTTL = time.Hour
+
+// newToken возвращает случайный токен из size байт в шестнадцатеричной записи
+func newToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("unable to generate token: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// pendingStore хранит проверенные данные до нажатия кнопки подтверждения.
+// Кнопка несет токен, по которому данные забираются один раз.
+type pendingStore[T any] struct {
+	mutex sync.Mutex
+	ttl   time.Duration
+	items map[string]pendingItem[T]
+}
+
+type pendingItem[T any] struct {
+	value   T
+	created time.Time
+}
+
+func newPendingStore[T any](ttl time.Duration) *pendingStore[T] {
+	return &pendingStore[T]{ttl: ttl, items: make(map[string]pendingItem[T])}
+}
+
+// put сохраняет value и возвращает токен для кнопки
+func (s *pendingStore[T]) put(value T) (string, error) {
+	token, err := newToken(8)
+	if err != nil {
+		return "", err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Убираем неподтвержденные записи, чтобы карта не росла бесконечно
+	for t, item := range s.items {
+		if time.Since(item.created) > s.ttl {
+			delete(s.items, t)
+		}
+	}
+	s.items[token] = pendingItem[T]{value: value, created: time.Now()}
+	return token, nil
+}
+
+// take возвращает данные и удаляет их, чтобы не применить дважды
+func (s *pendingStore[T]) take(token string) (T, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, ok := s.items[token]
+	delete(s.items, token)
+	if !ok || time.Since(item.created) > s.ttl {
+		var zero T
+		return zero, false
+	}
+	return item.value, true
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"bytes"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/models"
+	"telegram_verification_bot/internal/registry"
+)
+
+const maxRegistrySize = 5 << 20
+
+// handleImportRegistry загружает реестр собственников из CSV, присланного
+// админом документом с подписью /import_registry
+func (b *Bot) handleImportRegistry(message *tgbotapi.Message) {
+	lang := b.lang(message.From)
+	if message.Document == nil {
+		text := b.i18n.T(lang, "registry.usage")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	data, err := b.downloadDocument(message.Document, maxRegistrySize)
+	if err != nil {
+		log.Printf("Error downloading registry file: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "file.download_failed"))
+		b.send(msg)
+		return
+	}
+
+	entries, skipped, err := registry.ParseCSV(bytes.NewReader(data))
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "file.invalid", err))
+		b.send(msg)
+		return
+	}
+
+	if err := b.sheets.ReplaceRegistry(b.ctx, entries); err != nil {
+		log.Printf("Error importing registry: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "registry.save_failed"))
+		b.send(msg)
+		return
+	}
+
+	text := b.i18n.T(lang, "registry.imported", len(entries), skipped)
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	b.send(msg)
+}
+
+// registryMatch сверяет заявителя с реестром собственников
+func (b *Bot) registryMatch(user *models.User) (models.RegistryMatch, bool) {
+	entries, err := b.sheets.GetRegistry(b.ctx)
+	if err != nil {
+		log.Printf("Error loading registry: %v", err)
+		return models.RegistryMatch{}, false
+	}
+	if len(entries) == 0 {
+		return models.RegistryMatch{}, false
+	}
+	return models.MatchRegistry(entries, user), true
+}
+
+// formatRegistry формирует блок сверки с реестром для карточки модератора
+func (b *Bot) formatRegistry(lang string, user *models.User) string {
+	match, ok := b.registryMatch(user)
+	if !ok {
+		return ""
+	}
+	if len(match.Owners) == 0 {
+		return b.i18n.T(lang, "registry.card_missing", models.NormalizeAddress(user.Address))
+	}
+
+	var owners []string
+	for _, owner := range match.Owners {
+		owners = append(owners, owner.Owner)
+	}
+
+	phone, name := "❌", "❌"
+	if match.PhoneMatch {
+		phone = "✅"
+	}
+	if match.NameMatch {
+		name = "✅"
+	}
+	return b.i18n.T(lang, "registry.card", strings.Join(owners, ", "), phone, name)
+}
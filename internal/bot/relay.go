@@ -0,0 +1,289 @@
+package bot
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+)
+
+const (
+	defaultRelayLimit  = 5
+	defaultRelayWindow = time.Hour
+	relayContactTTL    = 7 * 24 * time.Hour
+)
+
+var (
+	errRelayUnknown     = errors.New("relay contact not found")
+	errRelayBlocked     = errors.New("relay blocked by recipient")
+	errRelayRateLimited = errors.New("relay rate limit exceeded")
+)
+
+// relayContact связывает владельца кнопки с собеседником, не раскрывая его ID
+type relayContact struct {
+	OwnerID int64
+	PeerID  int64
+	Created time.Time
+}
+
+// relayPair направленная пара отправитель -> получатель
+type relayPair struct {
+	From int64
+	To   int64
+}
+
+// relayManager хранит анонимные контакты, блокировки и лимиты переписки
+type relayManager struct {
+	mutex    sync.Mutex
+	contacts map[string]*relayContact
+	drafts   map[int64]string
+	blocked  map[relayPair]bool // кто заблокировал -> кого
+	history  map[relayPair][]time.Time
+	limit    int
+	window   time.Duration
+}
+
+func newRelayManager(limit int, window time.Duration) *relayManager {
+	if limit <= 0 {
+		limit = defaultRelayLimit
+	}
+	if window <= 0 {
+		window = defaultRelayWindow
+	}
+
+	return &relayManager{
+		contacts: make(map[string]*relayContact),
+		drafts:   make(map[int64]string),
+		blocked:  make(map[relayPair]bool),
+		history:  make(map[relayPair][]time.Time),
+		limit:    limit,
+		window:   window,
+	}
+}
+
+// newContact создает одноразовый токен, по которому ownerID может написать peerID
+func (r *relayManager) newContact(ownerID, peerID int64) (string, error) {
+	token, err := newToken(6)
+	if err != nil {
+		return "", err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// Убираем устаревшие контакты и историю пар, которые давно не писали,
+	// чтобы карты не росли бесконечно
+	now := time.Now()
+	for t, c := range r.contacts {
+		if now.Sub(c.Created) > relayContactTTL {
+			delete(r.contacts, t)
+		}
+	}
+	for pair, times := range r.history {
+		if len(times) == 0 || now.Sub(times[len(times)-1]) >= r.window {
+			delete(r.history, pair)
+		}
+	}
+
+	r.contacts[token] = &relayContact{OwnerID: ownerID, PeerID: peerID, Created: now}
+	return token, nil
+}
+
+// contact возвращает контакт по токену, если он принадлежит ownerID
+func (r *relayManager) contact(ownerID int64, token string) (*relayContact, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	c, ok := r.contacts[token]
+	if !ok || c.OwnerID != ownerID || time.Since(c.Created) > relayContactTTL {
+		return nil, errRelayUnknown
+	}
+	if r.blocked[relayPair{From: c.PeerID, To: c.OwnerID}] {
+		return nil, errRelayBlocked
+	}
+	return c, nil
+}
+
+// startDraft запоминает, что следующее сообщение ownerID нужно переслать по токену
+func (r *relayManager) startDraft(ownerID int64, token string) error {
+	if _, err := r.contact(ownerID, token); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.drafts[ownerID] = token
+	r.mutex.Unlock()
+	return nil
+}
+
+// hasDraft проверяет, ожидается ли от пользователя текст для пересылки
+func (r *relayManager) hasDraft(ownerID int64) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	_, ok := r.drafts[ownerID]
+	return ok
+}
+
+// takeDraft забирает ожидающую пересылку и проверяет лимиты пары
+func (r *relayManager) takeDraft(ownerID int64) (*relayContact, error) {
+	r.mutex.Lock()
+	token, ok := r.drafts[ownerID]
+	delete(r.drafts, ownerID)
+	r.mutex.Unlock()
+
+	if !ok {
+		return nil, errRelayUnknown
+	}
+
+	c, err := r.contact(ownerID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	pair := relayPair{From: c.OwnerID, To: c.PeerID}
+	now := time.Now()
+	var recent []time.Time
+	for _, t := range r.history[pair] {
+		if now.Sub(t) < r.window {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= r.limit {
+		r.history[pair] = recent
+		return nil, errRelayRateLimited
+	}
+	r.history[pair] = append(recent, now)
+
+	return c, nil
+}
+
+// block запрещает собеседнику из контакта писать владельцу
+func (r *relayManager) block(ownerID int64, token string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	c, ok := r.contacts[token]
+	if !ok || c.OwnerID != ownerID {
+		return errRelayUnknown
+	}
+
+	r.blocked[relayPair{From: c.OwnerID, To: c.PeerID}] = true
+	return nil
+}
+
+// createRelayButton создает кнопку "Написать" для результата поиска
+func (b *Bot) createRelayButton(lang string, ownerID, peerID int64, label string) tgbotapi.InlineKeyboardButton {
+	token, err := b.relay.newContact(ownerID, peerID)
+	if err != nil {
+		// Такая кнопка при нажатии обработается как устаревшая
+		log.Printf("Error creating relay contact: %v", err)
+		return tgbotapi.NewInlineKeyboardButtonData(b.i18n.T(lang, "relay.button", label), staleButtonData)
+	}
+	return b.actionButton(b.i18n.T(lang, "relay.button", label), "relay", 0, token)
+}
+
+// handleRelayStart включает режим написания сообщения соседу
+func (b *Bot) handleRelayStart(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	token := payload.Arg(0)
+	lang := b.lang(callback.From)
+
+	var text string
+	switch err := b.relay.startDraft(callback.From.ID, token); err {
+	case nil:
+		text = b.i18n.T(lang, "relay.start")
+	case errRelayBlocked:
+		text = b.i18n.T(lang, "relay.blocked")
+	default:
+		text = b.i18n.T(lang, "relay.stale")
+	}
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+	b.send(msg)
+}
+
+// handleRelayMessage пересылает текст собеседнику через бота
+func (b *Bot) handleRelayMessage(message *tgbotapi.Message) {
+	lang := b.lang(message.From)
+	if message.Text == "" {
+		text := b.i18n.T(lang, "relay.text_only")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	contact, err := b.relay.takeDraft(message.From.ID)
+	if err != nil {
+		var text string
+		switch err {
+		case errRelayBlocked:
+			text = b.i18n.T(lang, "relay.blocked")
+		case errRelayRateLimited:
+			text = b.i18n.T(lang, "relay.rate_limited")
+		default:
+			text = b.i18n.T(lang, "relay.stale")
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	sender, err := b.getUser(message.From.ID)
+	if err != nil {
+		text := b.i18n.T(lang, "error.generic")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	// Получатель отвечает по своему токену и не видит ID отправителя
+	replyToken, err := b.relay.newContact(contact.PeerID, contact.OwnerID)
+	if err != nil {
+		log.Printf("Error creating relay reply contact: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "relay.failed"))
+		b.send(msg)
+		return
+	}
+	peerLang := b.langOf(contact.PeerID)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			b.actionButton(b.i18n.T(peerLang, "relay.reply"), "relay", 0, replyToken),
+			b.actionButton(b.i18n.T(peerLang, "relay.block"), "relayblock", 0, replyToken),
+		),
+	)
+
+	text := b.i18n.T(peerLang, "relay.message",
+		sender.FirstName, sender.LastName, sender.Address, message.Text)
+	relayMsg := tgbotapi.NewMessage(contact.PeerID, text)
+	relayMsg.ReplyMarkup = keyboard
+	if _, err := b.send(relayMsg); err != nil {
+		log.Printf("Error relaying message: %v", err)
+		text := b.i18n.T(lang, "relay.failed")
+		msg := tgbotapi.NewMessage(message.Chat.ID, text)
+		b.send(msg)
+		return
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "relay.delivered"))
+	b.send(msg)
+}
+
+// handleRelayBlock блокирует дальнейшие сообщения от собеседника
+func (b *Bot) handleRelayBlock(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	token := payload.Arg(0)
+	lang := b.lang(callback.From)
+
+	text := b.i18n.T(lang, "relay.block_done")
+	if err := b.relay.block(callback.From.ID, token); err != nil {
+		text = b.i18n.T(lang, "error.button_outdated")
+	}
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, text)
+	b.send(msg)
+}
@@ -0,0 +1,183 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/config"
+	"telegram_verification_bot/internal/models"
+)
+
+const (
+	defaultPendingHours       = 48
+	defaultApplicantEveryDays = 7
+	maxDigestLines            = 30
+)
+
+// parseClock разбирает время суток вида "09:00"
+func parseClock(value string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// nextRun возвращает ближайший после now момент с временем hour:minute
+func nextRun(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// runReminders раз в день в reminders.digest_time присылает администратору
+// и модераторам сводку заявок, которые давно ждут решения
+func (b *Bot) runReminders(ctx context.Context) {
+	if b.config.Reminders.DigestTime == "" {
+		return
+	}
+	hour, minute, err := parseClock(b.config.Reminders.DigestTime)
+	if err != nil {
+		log.Printf("Reminders disabled: %v", err)
+		return
+	}
+
+	for {
+		timer := time.NewTimer(time.Until(nextRun(time.Now(), hour, minute)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		b.remindPending(time.Now())
+	}
+}
+
+// remindPending отправляет сводку администратору и модераторам и, если
+// включено, напоминания заявителям. Модераторы с доступом limited получают
+// сводку без телефонов.
+func (b *Bot) remindPending(now time.Time) {
+	hours := b.config.Reminders.PendingHours
+	if hours <= 0 {
+		hours = defaultPendingHours
+	}
+
+	users, err := b.sheets.GetAllUsers(b.ctx)
+	if err != nil {
+		log.Printf("Error loading users for reminders: %v", err)
+		return
+	}
+
+	var stale []*models.User
+	for _, user := range users {
+		if user.Status == models.StatusPending && now.Sub(user.RegisterDate) >= time.Duration(hours)*time.Hour {
+			stale = append(stale, user)
+		}
+	}
+	if len(stale) == 0 {
+		log.Printf("No pending applications older than %d hours", hours)
+		return
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].RegisterDate.Before(stale[j].RegisterDate)
+	})
+
+	msg := tgbotapi.NewMessage(b.config.AdminID, b.pendingDigest(b.langOf(b.config.AdminID), stale, now, hours, true, true))
+	b.send(msg)
+	for _, moderator := range b.config.Moderators {
+		if moderator.ID == b.config.AdminID {
+			continue
+		}
+		access, _ := b.config.Access(moderator.ID)
+		msg := tgbotapi.NewMessage(moderator.ID, b.pendingDigest(b.langOf(moderator.ID), stale, now, hours, access == config.AccessFull, false))
+		b.send(msg)
+	}
+
+	reminded := 0
+	if b.config.Reminders.NotifyApplicants {
+		reminded = b.remindApplicants(stale, now)
+	}
+	log.Printf("Pending digest sent: %d applications older than %d hours, %d applicants reminded", len(stale), hours, reminded)
+}
+
+// remindApplicants сообщает заявителям, что заявка в очереди, не чаще
+// reminders.applicant_every_days. Время напоминаний хранится во вкладке
+// Reminders, чтобы перезапуск не сбивал интервал. Заблокировавшим бота не пишет.
+func (b *Bot) remindApplicants(stale []*models.User, now time.Time) int {
+	days := b.config.Reminders.ApplicantEveryDays
+	if days <= 0 {
+		days = defaultApplicantEveryDays
+	}
+	every := time.Duration(days) * 24 * time.Hour
+
+	// Без истории напоминаний не пишем вовсе, иначе заявители получали бы их каждый день
+	last, err := b.sheets.GetReminders(b.ctx)
+	if err != nil {
+		log.Printf("Error loading reminder history, applicants are not reminded: %v", err)
+		return 0
+	}
+
+	reminded := 0
+	for _, user := range stale {
+		if b.isBlocked(user.TelegramID) {
+			continue
+		}
+		if at, ok := last[user.TelegramID]; ok && now.Sub(at) < every {
+			continue
+		}
+		lang := b.langOf(user.TelegramID)
+		msg := tgbotapi.NewMessage(user.TelegramID, b.i18n.T(lang, "reminder.pending"))
+		if _, err := b.send(msg); err != nil {
+			continue
+		}
+		reminded++
+		if err := b.sheets.SetReminded(b.ctx, user.TelegramID, now); err != nil {
+			log.Printf("Error saving reminder time for %d: %v", user.TelegramID, err)
+		}
+	}
+	return reminded
+}
+
+// pendingDigest составляет сводку заявок, ожидающих решения, на языке lang.
+// personal добавляет телефоны, canDecide — подсказку с командами администратора.
+func (b *Bot) pendingDigest(lang string, stale []*models.User, now time.Time, hours int, personal, canDecide bool) string {
+	var lines []string
+	for i, user := range stale {
+		if i == maxDigestLines {
+			lines = append(lines, b.i18n.T(lang, "list.more", len(stale)-maxDigestLines))
+			break
+		}
+		line := fmt.Sprintf("• %d %s %s, %s", user.TelegramID, user.FirstName, user.LastName, user.Address)
+		if personal && user.Phone != "" {
+			line += ", " + user.Phone
+		}
+		lines = append(lines, line+" — "+b.pendingAge(lang, user.RegisterDate, now))
+	}
+
+	hint := b.i18n.T(lang, "digest.hint_admin")
+	if canDecide {
+		hint = b.i18n.T(lang, "digest.hint_commands")
+	}
+	return b.i18n.T(lang, "digest.pending", hours, len(stale), strings.Join(lines, "\n"), hint)
+}
+
+// pendingAge описывает, сколько заявка ждет решения
+func (b *Bot) pendingAge(lang string, registered, now time.Time) string {
+	if registered.IsZero() {
+		return b.i18n.T(lang, "digest.no_date")
+	}
+	age := now.Sub(registered)
+	if age >= 48*time.Hour {
+		return b.i18n.T(lang, "digest.age_days", int(age.Hours())/24)
+	}
+	return b.i18n.T(lang, "digest.age_hours", int(age.Hours()))
+}
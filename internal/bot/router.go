@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+	"telegram_verification_bot/internal/i18n"
+)
+
+// request сообщение или нажатие inline кнопки, которое обрабатывает маршрутизатор
+type request struct {
+	from     *tgbotapi.User
+	chatID   int64
+	message  *tgbotapi.Message       // nil для нажатия кнопки
+	callback *tgbotapi.CallbackQuery // nil для сообщения
+	payload  *callbackdata.Payload   // проверенные данные кнопки действия
+	err      error                   // почему данные кнопки отклонены
+	route    string                  // маршрут, выбранный маршрутизатором
+	lang     string                  // язык интерфейса отправителя
+}
+
+func messageRequest(message *tgbotapi.Message) *request {
+	return &request{from: message.From, chatID: message.Chat.ID, message: message}
+}
+
+func callbackRequest(callback *tgbotapi.CallbackQuery) *request {
+	req := &request{from: callback.From, callback: callback}
+	if callback.Message != nil {
+		req.chatID = callback.Message.Chat.ID
+	}
+	return req
+}
+
+// handlerFunc обработчик маршрута
+type handlerFunc func(req *request)
+
+// middleware оборачивает обработчик: проверяет доступ, пишет журнал и т.п.
+type middleware func(next handlerFunc) handlerFunc
+
+// onMessage адаптирует обработчик, которому нужно само сообщение
+func onMessage(handler func(*tgbotapi.Message)) handlerFunc {
+	return func(req *request) {
+		if req.message != nil {
+			handler(req.message)
+		}
+	}
+}
+
+// onAction адаптирует обработчик кнопки действия
+func onAction(handler func(*tgbotapi.CallbackQuery, *callbackdata.Payload)) handlerFunc {
+	return func(req *request) {
+		if req.callback != nil && req.payload != nil {
+			handler(req.callback, req.payload)
+		}
+	}
+}
+
+// router сопоставляет командам, кнопкам меню и данным inline кнопок их обработчики
+type router struct {
+	codec      *callbackdata.Codec
+	catalog    *i18n.Catalog
+	commands   map[string]handlerFunc
+	buttons    map[string]handlerFunc // ключ текста кнопки в каталоге
+	callbacks  map[string]handlerFunc // точное совпадение данных кнопки
+	actions    map[string]handlerFunc // подписанные кнопки действий
+	durable    map[string]bool        // действия, кнопки которых не истекают
+	stale      handlerFunc            // кнопки с неверной подписью или истекшим сроком
+	fallback   handlerFunc            // сообщения без команды и кнопки
+	middleware []middleware
+}
+
+// newRouter создает маршрутизатор; middleware применяются ко всем маршрутам
+// в указанном порядке, первый — самый внешний
+func newRouter(codec *callbackdata.Codec, catalog *i18n.Catalog, middleware ...middleware) *router {
+	return &router{
+		codec:      codec,
+		catalog:    catalog,
+		commands:   make(map[string]handlerFunc),
+		buttons:    make(map[string]handlerFunc),
+		callbacks:  make(map[string]handlerFunc),
+		actions:    make(map[string]handlerFunc),
+		durable:    make(map[string]bool),
+		middleware: middleware,
+	}
+}
+
+// chain оборачивает обработчик в middleware, первый — самый внешний
+func chain(handler handlerFunc, middleware []middleware) handlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
+
+// command регистрирует команду; она же срабатывает в подписи к документу
+func (r *router) command(name string, handler handlerFunc, middleware ...middleware) {
+	r.commands[name] = chain(handler, middleware)
+}
+
+// button регистрирует кнопку постоянного меню по ключу ее текста в каталоге;
+// кнопка распознается на любом языке
+func (r *router) button(key string, handler handlerFunc, middleware ...middleware) {
+	r.buttons[key] = chain(handler, middleware)
+}
+
+// callback регистрирует inline кнопку с точными данными
+func (r *router) callback(data string, handler handlerFunc, middleware ...middleware) {
+	r.callbacks[data] = chain(handler, middleware)
+}
+
+// action регистрирует inline кнопки действия, закодированные callbackdata
+func (r *router) action(name string, handler handlerFunc, middleware ...middleware) {
+	r.actions[name] = chain(handler, middleware)
+}
+
+// durableAction регистрирует кнопки действия, которые работают и после
+// истечения срока; подпись и версия проверяются как обычно. Обработчик сам
+// проверяет, что действие еще имеет смысл.
+func (r *router) durableAction(name string, handler handlerFunc, middleware ...middleware) {
+	r.action(name, handler, middleware...)
+	r.durable[name] = true
+}
+
+// otherwiseStale регистрирует обработчик устаревших и поддельных кнопок
+func (r *router) otherwiseStale(handler handlerFunc, middleware ...middleware) {
+	r.stale = chain(handler, middleware)
+}
+
+// otherwise регистрирует обработчик прочих сообщений
+func (r *router) otherwise(handler handlerFunc, middleware ...middleware) {
+	r.fallback = chain(handler, middleware)
+}
+
+// isButton проверяет, является ли текст кнопкой меню
+func (r *router) isButton(text string) bool {
+	_, ok := r.buttonHandler(text)
+	return ok
+}
+
+// buttonHandler находит обработчик кнопки меню по ее тексту на любом языке
+func (r *router) buttonHandler(text string) (handlerFunc, bool) {
+	key, ok := r.catalog.Match("button.", text)
+	if !ok {
+		return nil, false
+	}
+	handler, ok := r.buttons[key]
+	return handler, ok
+}
+
+// match находит обработчик запроса и имя маршрута для журнала
+func (r *router) match(req *request) (handlerFunc, string) {
+	if req.callback != nil {
+		data := req.callback.Data
+		if handler, ok := r.callbacks[data]; ok {
+			return handler, "callback " + data
+		}
+
+		// Кнопки старого формата и с истекшим сроком тоже считаются устаревшими
+		payload, err := r.codec.Decode(data)
+		if errors.Is(err, callbackdata.ErrExpired) && r.durable[payload.Action] {
+			err = nil
+		}
+		if err != nil {
+			req.err = err
+			return r.stale, "callback stale"
+		}
+		handler, ok := r.actions[payload.Action]
+		if !ok {
+			req.err = fmt.Errorf("unknown action %q", payload.Action)
+			return r.stale, "callback stale"
+		}
+		req.payload = payload
+		return handler, "callback " + payload.Action
+	}
+
+	message := req.message
+	if message.IsCommand() {
+		// Неизвестные команды игнорируются
+		return r.commands[message.Command()], "/" + message.Command()
+	}
+	if command := captionCommand(message); command != "" {
+		if handler, ok := r.commands[command]; ok {
+			return handler, "/" + command
+		}
+	}
+	if handler, ok := r.buttonHandler(message.Text); ok {
+		return handler, "button " + message.Text
+	}
+	return r.fallback, "message"
+}
+
+// serve выполняет обработчик запроса вместе с общими middleware
+func (r *router) serve(req *request) {
+	handler, route := r.match(req)
+	if handler == nil {
+		return
+	}
+	req.route = route
+	chain(handler, r.middleware)(req)
+}
@@ -0,0 +1,65 @@
+package bot
+
+// routes регистрирует команды, кнопки меню и inline кнопки бота
+func (b *Bot) routes() *router {
+	r := newRouter(b.codec, b.i18n, b.recoverPanics, b.logRequests, b.rateLimit)
+
+	// Пользовательские команды и кнопки постоянного меню
+	r.command("start", b.handleStart)
+	r.button("button.menu", b.handleStart)
+	r.command("register", b.handleRegister)
+	r.button("button.register", b.handleRegister)
+	r.command("status", b.handleStatus)
+	r.button("button.status", b.handleStatus)
+	r.command("help", b.handleHelp)
+	r.button("button.help", b.handleHelp)
+	r.command("language", b.handleLanguage)
+
+	// Команды администратора
+	r.command("users", b.handleListUsers, b.adminOnly)
+	r.button("button.users", b.handleListUsers, b.adminOnly)
+	r.button("button.search", b.handleAdminSearchMode, b.adminOnly)
+	r.command("approve", onMessage(b.handleModeration), b.adminOnly)
+	r.command("reject", onMessage(b.handleModeration), b.adminOnly)
+	r.command("outbox", onMessage(b.handleOutbox), b.adminOnly)
+	r.command("owner", onMessage(b.handleSetOwner), b.adminOnly)
+	r.command("import_registry", onMessage(b.handleImportRegistry), b.adminOnly)
+	r.command("bulk", onMessage(b.handleBulkUpload), b.adminOnly)
+	r.command("queue", onMessage(b.handleQueue), b.adminOnly)
+	r.command("templates", onMessage(b.handleTemplates), b.adminOnly)
+	r.command("broadcast", onMessage(b.handleBroadcast), b.adminOnly)
+
+	// Выгрузка доступна модераторам, уровень доступа проверяет обработчик
+	r.command("export", onMessage(b.handleExport))
+
+	// Inline кнопки главного меню
+	r.callback("register", b.handleRegister)
+	r.callback("status", b.handleStatus)
+	r.callback("help", b.handleHelp)
+	r.callback("admin_users", b.handleListUsers, b.adminOnly)
+	r.callback("admin_search", b.handleAdminSearchMode, b.adminOnly)
+
+	// Кнопки действий с подписанными данными, см. actionButton. Заявка может
+	// ждать решения дольше срока кнопок, а ошибочное автоматическое решение
+	// может обнаружиться позже, поэтому кнопки модерации и отмены не истекают.
+	r.durableAction("approve", onAction(b.handleInlineApproval), b.adminOnly)
+	r.durableAction("reject", onAction(b.handleInlineRejection), b.adminOnly)
+	r.durableAction("revert", onAction(b.handleRevertDecision), b.adminOnly)
+	r.action("bulk", onAction(b.handleBulkCallback), b.adminOnly)
+	r.action("bulkcancel", onAction(b.handleBulkCallback), b.adminOnly)
+	r.action("tplsave", onAction(b.handleTemplateCallback), b.adminOnly)
+	r.action("tplcancel", onAction(b.handleTemplateCallback), b.adminOnly)
+	r.action("bcsend", onAction(b.handleBroadcastCallback), b.adminOnly)
+	r.action("bccancel", onAction(b.handleBroadcastCallback), b.adminOnly)
+	r.action("vouch", onAction(b.handleNeighborVouch))
+	r.action("hhvouch", onAction(b.handleOwnerVouch))
+	r.action("relay", onAction(b.handleRelayStart))
+	r.action("relayblock", onAction(b.handleRelayBlock))
+	r.action("language", onAction(b.handleLanguageChoice))
+	r.otherwiseStale(b.handleStaleCallback)
+
+	// Черновик сообщения соседу, шаг регистрации или поиск
+	r.otherwise(onMessage(b.handleText))
+
+	return r
+}
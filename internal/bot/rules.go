@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+	"telegram_verification_bot/internal/i18n"
+	"telegram_verification_bot/internal/models"
+	"telegram_verification_bot/internal/rules"
+)
+
+// applyRules проверяет ожидающую заявку по правилам из конфигурации.
+// Возвращает true, если правило одобрило или отклонило заявку.
+func (b *Bot) applyRules(applicant *models.User) bool {
+	if b.rules.Empty() {
+		return false
+	}
+
+	user, err := b.getUser(applicant.TelegramID)
+	if err != nil || user.Status != models.StatusPending {
+		return false
+	}
+
+	// Правило срабатывает для заявки один раз, в том числе если его решение отменили
+	entries, err := b.sheets.GetAuditEntries(b.ctx, user.TelegramID)
+	if err != nil {
+		log.Printf("Error loading audit entries: %v", err)
+		return false
+	}
+	skip := make(map[string]bool)
+	for _, entry := range entries {
+		skip[entry.Rule] = true
+	}
+
+	facts := rules.Facts{User: user}
+	if b.rules.UsesAllowlist() {
+		phones, err := b.sheets.GetPhoneAllowlist(b.ctx)
+		if err != nil {
+			log.Printf("Error loading phone allowlist: %v", err)
+		}
+		facts.PhoneAllowed = phones[models.NormalizePhone(user.Phone)]
+	}
+	if b.rules.UsesRegistry() {
+		match, _ := b.registryMatch(user)
+		facts.RegistryPhone = match.PhoneMatch
+		facts.RegistryName = match.NameMatch
+	}
+	if b.rules.UsesVouches() {
+		facts.Vouches, _, _ = b.countVouches(user)
+	}
+
+	decision := b.rules.Evaluate(facts, skip)
+	if decision == nil {
+		return false
+	}
+	return b.applyDecision(user, decision)
+}
+
+// applyDecision выполняет автоматическое решение и записывает его в журнал.
+// Возвращает true, если статус заявки изменен.
+func (b *Bot) applyDecision(user *models.User, decision *rules.Decision) bool {
+	entry := &models.AuditEntry{
+		ID:         strconv.FormatInt(time.Now().UnixNano(), 36),
+		CreatedAt:  time.Now(),
+		TelegramID: user.TelegramID,
+		Rule:       decision.Rule,
+		Action:     string(decision.Action),
+		Role:       decision.Role,
+		Comment:    decision.Comment,
+	}
+	// Без записи в журнале решение нельзя будет отменить, поэтому не применяем его
+	if err := b.sheets.AddAuditEntry(b.ctx, entry); err != nil {
+		log.Printf("Error writing audit entry: %v", err)
+		return false
+	}
+
+	lang := b.langOf(b.config.AdminID)
+	name := fmt.Sprintf("%s %s (ID: %d)", user.FirstName, user.LastName, user.TelegramID)
+
+	if decision.Action == rules.ActionFlag {
+		text := b.i18n.T(lang, "rules.flagged", name, decision.Rule, decision.Comment)
+		msg := tgbotapi.NewMessage(b.config.AdminID, text)
+		msg.ReplyMarkup = b.createModerationMenu(lang, user.TelegramID)
+		b.send(msg)
+		return false
+	}
+
+	status := models.StatusApproved
+	if decision.Action == rules.ActionReject {
+		status = models.StatusRejected
+	}
+
+	updatedBy := b.i18n.T(i18n.DefaultLanguage, "rules.updated_by", decision.Rule)
+	err := b.setUserStatus(user.TelegramID, status, decision.Role, decision.Comment, updatedBy)
+	if err != nil {
+		log.Printf("Error applying rule %q: %v", decision.Rule, err)
+		return false
+	}
+
+	var adminText string
+	if status == models.StatusApproved {
+		adminText = b.i18n.T(lang, "rules.approved", name, decision.Rule, b.roleName(lang, decision.Role))
+	} else {
+		adminText = b.i18n.T(lang, "rules.rejected", name, decision.Rule, decision.Comment)
+	}
+
+	if err := b.notifyDecision(user.TelegramID, status, decision.Role, decision.Comment); err != nil {
+		adminText += "\n" + b.i18n.T(lang, "moderation.undelivered") + " " + b.sendErrorText(lang, err)
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			b.actionButton(b.i18n.T(lang, "rules.revert"), "revert", user.TelegramID, entry.ID),
+		),
+	)
+	msg := tgbotapi.NewMessage(b.config.AdminID, adminText)
+	msg.ReplyMarkup = keyboard
+	b.send(msg)
+	return true
+}
+
+// decisionInEffect проверяет, что заявка все еще в том состоянии, в которое
+// ее перевело автоматическое решение
+func decisionInEffect(entry *models.AuditEntry, user *models.User) bool {
+	switch rules.Action(entry.Action) {
+	case rules.ActionApprove:
+		return user.Status == models.StatusApproved && user.Role == entry.Role
+	case rules.ActionReject:
+		return user.Status == models.StatusRejected
+	}
+	return false
+}
+
+// handleRevertDecision отменяет автоматическое решение и возвращает заявку на рассмотрение
+func (b *Bot) handleRevertDecision(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	id := payload.Arg(0)
+	lang := b.lang(callback.From)
+
+	entry, err := b.sheets.GetAuditEntry(b.ctx, id)
+	if err != nil {
+		log.Printf("Error loading audit entry: %v", err)
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "rules.entry_not_found"))
+		b.send(msg)
+		return
+	}
+	if entry.RevertedBy != "" {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "rules.already_reverted", entry.RevertedBy))
+		b.send(msg)
+		return
+	}
+
+	// После автоматического решения модератор мог изменить заявку вручную,
+	// тогда отмена вернула бы на рассмотрение уже чужое решение
+	user, err := b.getUser(entry.TelegramID)
+	if err != nil {
+		log.Printf("Error loading user for revert: %v", err)
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "rules.status_unknown"))
+		b.send(msg)
+		return
+	}
+	if !decisionInEffect(entry, user) {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "rules.already_changed",
+			user.Status, b.roleName(lang, user.Role)))
+		b.send(msg)
+		return
+	}
+
+	moderator := moderatorName(callback.From)
+	comment := b.i18n.T(i18n.DefaultLanguage, "rules.reverted_comment", entry.Rule)
+	err = b.setUserStatus(entry.TelegramID, models.StatusPending, models.RoleGuest, comment, moderator)
+	if err != nil {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.statusErrorText(lang, err))
+		b.send(msg)
+		return
+	}
+	if _, err := b.sheets.MarkAuditReverted(b.ctx, id, moderator); err != nil {
+		log.Printf("Error marking audit entry reverted: %v", err)
+	}
+
+	userMsg := tgbotapi.NewMessage(entry.TelegramID, b.i18n.T(b.langOf(entry.TelegramID), "decision.reverted"))
+	text := callback.Message.Text + b.i18n.T(lang, "rules.reverted")
+	if _, err := b.send(userMsg); err != nil {
+		text += "\n" + b.i18n.T(lang, "moderation.undelivered") + " " + b.sendErrorText(lang, err)
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	b.send(editMsg)
+
+	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "rules.pending_again", entry.TelegramID))
+	msg.ReplyMarkup = b.createModerationMenu(lang, entry.TelegramID)
+	b.send(msg)
+}
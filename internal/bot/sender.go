@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	defaultSendAttempts = 3
+	sendBaseBackoff     = time.Second
+	// maxFloodWait дольше этого бот не ждет по retry_after, чтобы не держать обработчик
+	maxFloodWait = time.Minute
+)
+
+// errBlocked пользователь заблокировал бота или удалил аккаунт
+var errBlocked = errors.New("bot was blocked by the user")
+
+// sender отправляет сообщения в Telegram: ждет retry_after при ограничении
+// частоты, повторяет отправку при временных ошибках и сообщает о
+// пользователях, заблокировавших бота
+type sender struct {
+	api         *tgbotapi.BotAPI
+	maxAttempts int
+	onBlocked   func(chatID int64, reason string)
+}
+
+func newSender(api *tgbotapi.BotAPI, maxAttempts int, onBlocked func(chatID int64, reason string)) *sender {
+	if maxAttempts <= 0 {
+		maxAttempts = defaultSendAttempts
+	}
+	return &sender{api: api, maxAttempts: maxAttempts, onBlocked: onBlocked}
+}
+
+// send отправляет сообщение. Ошибка для заблокировавшего бота пользователя
+// оборачивает errBlocked.
+func (s *sender) send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	chatID := chatIDOf(c)
+
+	var err error
+	for attempt := 0; attempt < s.maxAttempts; attempt++ {
+		var msg tgbotapi.Message
+		msg, err = s.api.Send(c)
+		if err == nil {
+			return msg, nil
+		}
+
+		if isBlockedError(err) {
+			// Отрицательные ID — группы, их не отмечаем
+			if chatID > 0 && s.onBlocked != nil {
+				s.onBlocked(chatID, err.Error())
+			}
+			return tgbotapi.Message{}, fmt.Errorf("%w: %v", errBlocked, err)
+		}
+
+		delay, retry := sendRetryDelay(attempt, err)
+		if !retry || attempt == s.maxAttempts-1 {
+			break
+		}
+		log.Printf("Telegram send to %d failed (attempt %d/%d), retrying in %v: %v",
+			chatID, attempt+1, s.maxAttempts, delay, err)
+		time.Sleep(delay)
+	}
+
+	log.Printf("Error sending message to %d: %v", chatID, err)
+	return tgbotapi.Message{}, err
+}
+
+// isBlockedError определяет ответ Telegram 403: бот заблокирован, аккаунт
+// удален или пользователь еще не писал боту
+func isBlockedError(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return false
+	}
+	// При отправке файлов библиотека не заполняет код ошибки
+	return tgErr.Code == 403 || strings.HasPrefix(tgErr.Message, "Forbidden:")
+}
+
+// sendRetryDelay возвращает задержку перед повтором и false, если повторять
+// не имеет смысла
+func sendRetryDelay(attempt int, err error) (time.Duration, bool) {
+	delay := sendBaseBackoff << uint(attempt)
+
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		if tgErr.RetryAfter > 0 {
+			wait := time.Duration(tgErr.RetryAfter) * time.Second
+			return wait, wait <= maxFloodWait
+		}
+		return delay, tgErr.Code >= 500
+	}
+
+	// Сетевые ошибки и таймауты; ошибки разбора ответа не повторяем,
+	// сообщение могло уже уйти
+	var netErr net.Error
+	return delay, errors.As(err, &netErr)
+}
+
+// chatIDOf возвращает чат, в который отправляется сообщение
+func chatIDOf(c tgbotapi.Chattable) int64 {
+	switch c := c.(type) {
+	case tgbotapi.MessageConfig:
+		return c.ChatID
+	case tgbotapi.PhotoConfig:
+		return c.ChatID
+	case tgbotapi.DocumentConfig:
+		return c.ChatID
+	case tgbotapi.EditMessageTextConfig:
+		return c.ChatID
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return c.ChatID
+	}
+	return 0
+}
+
+// send отправляет сообщение через sender
+func (b *Bot) send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	return b.sender.send(c)
+}
+
+// sendErrorText описывает ошибку отправки для модератора
+func (b *Bot) sendErrorText(lang string, err error) string {
+	if errors.Is(err, errBlocked) {
+		return b.i18n.T(lang, "moderation.bot_blocked")
+	}
+	return err.Error()
+}
+
+// blockedStore пользователи, которым бот не может писать
+type blockedStore struct {
+	mutex sync.Mutex
+	users map[int64]string
+}
+
+func newBlockedStore() *blockedStore {
+	return &blockedStore{users: make(map[int64]string)}
+}
+
+// loadBlocked загружает заблокировавших бота пользователей из таблицы
+func (b *Bot) loadBlocked() error {
+	blocked, err := b.sheets.GetBlocked(b.ctx)
+	if err != nil {
+		return err
+	}
+
+	b.blocked.mutex.Lock()
+	defer b.blocked.mutex.Unlock()
+	for userID, reason := range blocked {
+		b.blocked.users[userID] = reason
+	}
+	return nil
+}
+
+// isBlocked сообщает, заблокировал ли пользователь бота
+func (b *Bot) isBlocked(userID int64) bool {
+	b.blocked.mutex.Lock()
+	defer b.blocked.mutex.Unlock()
+	_, ok := b.blocked.users[userID]
+	return ok
+}
+
+// markBlocked запоминает пользователя, заблокировавшего бота
+func (b *Bot) markBlocked(userID int64, reason string) {
+	b.blocked.mutex.Lock()
+	_, known := b.blocked.users[userID]
+	b.blocked.users[userID] = reason
+	b.blocked.mutex.Unlock()
+	if known {
+		return
+	}
+
+	log.Printf("User %d blocked the bot: %s", userID, reason)
+	b.spawn(func() {
+		if err := b.sheets.SetBlocked(b.ctx, userID, reason); err != nil {
+			log.Printf("Error saving blocked user %d: %v", userID, err)
+		}
+	})
+}
+
+// markReachable снимает отметку, когда пользователь снова пишет боту
+func (b *Bot) markReachable(userID int64) {
+	b.blocked.mutex.Lock()
+	_, known := b.blocked.users[userID]
+	delete(b.blocked.users, userID)
+	b.blocked.mutex.Unlock()
+	if !known {
+		return
+	}
+
+	b.spawn(func() {
+		if err := b.sheets.ClearBlocked(b.ctx, userID); err != nil {
+			log.Printf("Error clearing blocked user %d: %v", userID, err)
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/models"
+)
+
+const (
+	defaultDraftsPath      = "./data/drafts.json"
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+// spawn запускает обработчик, которого остановка бота будет ждать
+func (b *Bot) spawn(fn func()) {
+	b.handlers.Add(1)
+	go func() {
+		defer b.handlers.Done()
+		fn()
+	}()
+}
+
+// dispatch ставит обновление в очередь обработчика его отправителя
+func (b *Bot) dispatch(update tgbotapi.Update) {
+	if update.Message != nil {
+		b.dispatcher.submit(updateKey(update), func() { b.handleMessage(update.Message) })
+	} else if update.CallbackQuery != nil {
+		b.dispatcher.submit(updateKey(update), func() { b.handleCallbackQuery(update.CallbackQuery) })
+	}
+}
+
+// shutdown останавливает прием обновлений, дожидается обработчиков,
+// отправляет журнал записей и сохраняет черновики регистрации
+func (b *Bot) shutdown(updates tgbotapi.UpdatesChannel) {
+	timeout := time.Duration(b.config.ShutdownTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Перестаем принимать обновления. Пока сервер webhook дожидается своих
+	// запросов, их обновления продолжают уходить обработчикам.
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if b.server != nil {
+			if err := b.server.Shutdown(ctx); err != nil {
+				log.Printf("Error stopping webhook server: %v", err)
+			}
+		} else {
+			b.api.StopReceivingUpdates()
+		}
+	}()
+	for receiving := true; receiving; {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			b.dispatch(update)
+		case <-stopped:
+			receiving = false
+		}
+	}
+
+	// Полученные, но не разобранные обновления Telegram уже не пришлет повторно
+	for drained := false; !drained; {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				drained = true
+				break
+			}
+			b.dispatch(update)
+		default:
+			drained = true
+		}
+	}
+
+	// Ждем обработчики и очереди не дольше таймаута
+	b.dispatcher.close()
+	done := make(chan struct{})
+	go func() {
+		b.dispatcher.wait()
+		b.handlers.Wait()
+		close(done)
+	}()
+	finished := false
+	select {
+	case <-done:
+		finished = true
+		log.Println("All handlers finished")
+	case <-ctx.Done():
+		log.Println("Shutdown timeout: some handlers are still running")
+	}
+
+	// Записи, которые не удастся отправить, останутся в файле журнала до запуска
+	if left := b.outbox.Flush(b.applyOutboxItem); left > 0 {
+		log.Printf("%d outbox records will be sent after restart", left)
+	}
+
+	// Незавершенный обработчик может менять регистрацию прямо сейчас, и
+	// сохраненный черновик оказался бы несогласованным
+	if !finished {
+		log.Println("Registration drafts are not saved: handlers are still running")
+		return
+	}
+	if err := b.saveDrafts(); err != nil {
+		log.Printf("Error saving registration drafts: %v", err)
+	}
+}
+
+// draftsPath возвращает файл черновиков регистрации
+func (b *Bot) draftsPath() string {
+	if b.config.DraftsPath != "" {
+		return b.config.DraftsPath
+	}
+	return defaultDraftsPath
+}
+
+// saveDrafts сохраняет незавершенные регистрации, чтобы продолжить их после перезапуска
+func (b *Bot) saveDrafts() error {
+	b.mutex.RLock()
+	drafts := make([]*models.RegistrationState, 0, len(b.registrations))
+	for _, reg := range b.registrations {
+		drafts = append(drafts, reg)
+	}
+	b.mutex.RUnlock()
+
+	path := b.draftsPath()
+	if len(drafts) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(drafts, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode drafts: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("unable to create drafts directory: %v", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("unable to write drafts: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("unable to write drafts: %v", err)
+	}
+
+	log.Printf("Saved %d registration drafts", len(drafts))
+	return nil
+}
+
+// loadDrafts восстанавливает регистрации, сохраненные при остановке
+func (b *Bot) loadDrafts() error {
+	path := b.draftsPath()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read drafts: %v", err)
+	}
+
+	var drafts []*models.RegistrationState
+	if err := json.Unmarshal(data, &drafts); err != nil {
+		return fmt.Errorf("unable to parse drafts: %v", err)
+	}
+
+	b.mutex.Lock()
+	for _, reg := range drafts {
+		b.registrations[reg.TelegramID] = reg
+	}
+	b.mutex.Unlock()
+
+	// Файл описывает только последнюю остановку, после аварийного
+	// завершения старые черновики не должны вернуться
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("unable to remove drafts: %v", err)
+	}
+
+	log.Printf("Restored %d registration drafts", len(drafts))
+	return nil
+}
@@ -0,0 +1,231 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+	"telegram_verification_bot/internal/config"
+	"telegram_verification_bot/internal/templates"
+)
+
+// templateDraft шаблон, ожидающий подтверждения после предпросмотра
+type templateDraft struct {
+	AdminID int64
+	Key     string
+	Text    string
+}
+
+// configTemplates проверяет шаблоны из конфигурации. Суффикс языка в ключе
+// должен быть одним из languages.
+func configTemplates(cfg *config.Config, languages []string) (*templates.Set, error) {
+	set := templates.NewSet(languages)
+	for key, text := range cfg.Templates {
+		if err := set.Put(key, text, templates.OriginConfig); err != nil {
+			return nil, fmt.Errorf("template %s: %v", key, err)
+		}
+	}
+	return set, nil
+}
+
+// loadTemplates собирает шаблоны из конфигурации и таблицы. Шаблоны из
+// таблицы важнее; ошибочные пропускаются, чтобы не сломать отправку.
+func (b *Bot) loadTemplates() error {
+	set, err := configTemplates(b.config, b.i18n.Languages())
+	if err != nil {
+		return err
+	}
+
+	stored, err := b.sheets.GetTemplates(b.ctx)
+	if err != nil {
+		return err
+	}
+	for key, text := range stored {
+		if text == "" {
+			continue
+		}
+		if err := set.Put(key, text, templates.OriginSheet); err != nil {
+			log.Printf("Skipping invalid template %s from sheet: %v", key, err)
+		}
+	}
+
+	b.templates.Replace(set)
+	return nil
+}
+
+// renderTemplate возвращает текст шаблона name для языка lang или fallback,
+// если шаблон не задан или не выполнился
+func (b *Bot) renderTemplate(name templates.Name, lang string, data templates.Data, fallback string) string {
+	text, err := b.templates.Render(name, lang, data)
+	if err != nil {
+		log.Printf("Error rendering template %s: %v", name, err)
+		return fallback
+	}
+	if text == "" {
+		return fallback
+	}
+	return text
+}
+
+// handleTemplates показывает и меняет шаблоны сообщений:
+// /templates, /templates show KEY, /templates set KEY + текст, /templates reset KEY
+func (b *Bot) handleTemplates(message *tgbotapi.Message) {
+	args := message.CommandArguments()
+	firstLine, body, _ := strings.Cut(args, "\n")
+	fields := strings.Fields(firstLine)
+	lang := b.lang(message.From)
+
+	if len(fields) == 0 {
+		b.sendTemplateList(message.Chat.ID, lang)
+		return
+	}
+	if len(fields) < 2 {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "template.key_required"))
+		b.send(msg)
+		return
+	}
+
+	name, keyLang, err := templates.ParseKey(fields[1], b.i18n.Languages())
+	if err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "template.unknown",
+			fields[1], strings.Join(b.i18n.Languages(), ", ")))
+		b.send(msg)
+		return
+	}
+	key := templates.Key(name, keyLang)
+
+	switch strings.ToLower(fields[0]) {
+	case "show":
+		text, origin, ok := b.templates.Source(key)
+		if ok {
+			origin = b.originText(lang, origin)
+		} else {
+			text, origin = b.i18n.T(lang, "template.not_set"), "—"
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "template.show", key, origin, text))
+		b.send(msg)
+
+	case "set":
+		b.previewTemplate(message, key, strings.TrimSpace(body))
+
+	case "reset":
+		if err := b.sheets.SetTemplate(b.ctx, key, "", moderatorName(message.From)); err != nil {
+			log.Printf("Error resetting template: %v", err)
+			msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "template.save_failed"))
+			b.send(msg)
+			return
+		}
+		if err := b.loadTemplates(); err != nil {
+			log.Printf("Error reloading templates: %v", err)
+		}
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "template.reset", key))
+		b.send(msg)
+
+	default:
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(lang, "template.unknown_action"))
+		b.send(msg)
+	}
+}
+
+// originText называет источник шаблона на языке lang
+func (b *Bot) originText(lang, origin string) string {
+	if origin == templates.OriginSheet {
+		return b.i18n.T(lang, "template.origin_sheet")
+	}
+	return b.i18n.T(lang, "template.origin_config")
+}
+
+// sendTemplateList перечисляет шаблоны и подсказывает формат команды
+func (b *Bot) sendTemplateList(chatID int64, lang string) {
+	var lines []string
+	for _, name := range templates.Names {
+		key := string(name)
+		if _, origin, ok := b.templates.Source(key); ok {
+			lines = append(lines, b.i18n.T(lang, "template.item", key, b.originText(lang, origin)))
+		} else {
+			lines = append(lines, b.i18n.T(lang, "template.item", key, b.i18n.T(lang, "template.builtin")))
+		}
+	}
+	for _, key := range b.templates.Keys() {
+		if _, keyLang, _ := templates.ParseKey(key, b.i18n.Languages()); keyLang != "" {
+			_, origin, _ := b.templates.Source(key)
+			lines = append(lines, b.i18n.T(lang, "template.item_lang", key, b.originText(lang, origin), keyLang))
+		}
+	}
+
+	text := b.i18n.T(lang, "template.list",
+		strings.Join(lines, "\n"), "{{."+strings.Join(templates.Fields, "}}, {{.")+"}}")
+	msg := tgbotapi.NewMessage(chatID, text)
+	b.send(msg)
+}
+
+// previewTemplate проверяет шаблон и показывает его на примере данных
+// с кнопками сохранения
+func (b *Bot) previewTemplate(message *tgbotapi.Message, key, text string) {
+	adminLang := b.lang(message.From)
+	if text == "" {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(adminLang, "template.text_required", key))
+		b.send(msg)
+		return
+	}
+
+	// Проверяем так же, как при загрузке, чтобы ошибочный шаблон не попал в таблицу
+	check := templates.NewSet(b.i18n.Languages())
+	if err := check.Put(key, text, templates.OriginSheet); err != nil {
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(adminLang, "template.invalid", err))
+		b.send(msg)
+		return
+	}
+	name, lang, _ := templates.ParseKey(key, b.i18n.Languages())
+	preview, _ := check.Render(name, lang, templates.Sample())
+
+	token, err := b.templateDrafts.put(&templateDraft{AdminID: message.From.ID, Key: key, Text: text})
+	if err != nil {
+		log.Printf("Error storing template draft: %v", err)
+		msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(adminLang, "template.store_failed"))
+		b.send(msg)
+		return
+	}
+	msg := tgbotapi.NewMessage(message.Chat.ID, b.i18n.T(adminLang, "template.preview", key, preview))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			b.actionButton(b.i18n.T(adminLang, "template.save"), "tplsave", 0, token),
+			b.actionButton(b.i18n.T(adminLang, "button.cancel"), "tplcancel", 0, token),
+		),
+	)
+	b.send(msg)
+}
+
+// handleTemplateCallback сохраняет или отменяет шаблон после предпросмотра
+func (b *Bot) handleTemplateCallback(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	lang := b.lang(callback.From)
+	draft, ok := b.templateDrafts.take(payload.Arg(0))
+	if !ok || draft.AdminID != callback.From.ID {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "template.stale"))
+		b.send(msg)
+		return
+	}
+
+	if payload.Action == "tplcancel" {
+		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+			callback.Message.Text+b.i18n.T(lang, "action.cancelled"))
+		b.send(editMsg)
+		return
+	}
+
+	if err := b.sheets.SetTemplate(b.ctx, draft.Key, draft.Text, moderatorName(callback.From)); err != nil {
+		log.Printf("Error saving template: %v", err)
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "template.save_failed"))
+		b.send(msg)
+		return
+	}
+	if err := b.loadTemplates(); err != nil {
+		log.Printf("Error reloading templates: %v", err)
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID,
+		callback.Message.Text+b.i18n.T(lang, "template.saved"))
+	b.send(editMsg)
+}
@@ -0,0 +1,262 @@
+package bot
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"telegram_verification_bot/internal/callbackdata"
+	"telegram_verification_bot/internal/i18n"
+	"telegram_verification_bot/internal/models"
+	"telegram_verification_bot/internal/rules"
+)
+
+const (
+	maxVouchers          = 3
+	defaultVouchRequired = 2
+	// vouchRule правило в журнале решений, по которому заявка проверяется один раз
+	vouchRule = "подтверждение соседями"
+)
+
+// resolveVouchers находит одобренных жителей по @username или телефону,
+// перечисленным через запятую
+func (b *Bot) resolveVouchers(applicantID int64, input string) ([]*models.User, []string) {
+	users, err := b.sheets.GetAllUsers(b.ctx)
+	if err != nil {
+		log.Printf("Error loading users for vouching: %v", err)
+		return nil, nil
+	}
+
+	var found []*models.User
+	var notFound []string
+	seen := make(map[int64]bool)
+
+	for _, ref := range strings.Split(input, ",") {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			continue
+		}
+		username := strings.ToLower(strings.TrimPrefix(ref, "@"))
+		phone := models.NormalizePhone(ref)
+
+		var match *models.User
+		for _, user := range users {
+			if user.Status != models.StatusApproved || user.TelegramID == applicantID {
+				continue
+			}
+			if (user.Username != "" && strings.ToLower(user.Username) == username) ||
+				(len(phone) >= 10 && models.NormalizePhone(user.Phone) == phone) {
+				match = user
+				break
+			}
+		}
+
+		if match == nil {
+			notFound = append(notFound, ref)
+			continue
+		}
+		if !seen[match.TelegramID] && len(found) < maxVouchers {
+			seen[match.TelegramID] = true
+			found = append(found, match)
+		}
+	}
+
+	return found, notFound
+}
+
+// requestNeighborVouches записывает запросы подтверждения и отправляет их соседям
+func (b *Bot) requestNeighborVouches(applicant *models.User, vouchers []int64) {
+	for _, voucherID := range vouchers {
+		err := b.sheets.AddVouch(b.ctx, &models.Vouch{
+			ApplicantID: applicant.TelegramID,
+			VoucherID:   voucherID,
+			Kind:        models.VouchNeighbor,
+			Status:      models.VouchPending,
+			CreatedAt:   time.Now(),
+		})
+		if err != nil {
+			log.Printf("Error saving vouch request: %v", err)
+			continue
+		}
+
+		lang := b.langOf(voucherID)
+		text := b.i18n.T(lang, "vouch.request",
+			applicant.FirstName, applicant.LastName, applicant.Username, applicant.Address)
+
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				b.actionButton(b.i18n.T(lang, "vouch.confirm"), "vouch", applicant.TelegramID, "yes"),
+				b.actionButton(b.i18n.T(lang, "vouch.deny"), "vouch", applicant.TelegramID, "no"),
+			),
+		)
+
+		msg := tgbotapi.NewMessage(voucherID, text)
+		msg.ReplyMarkup = keyboard
+		b.send(msg)
+	}
+}
+
+// handleNeighborVouch обрабатывает ответ соседа на запрос подтверждения
+func (b *Bot) handleNeighborVouch(callback *tgbotapi.CallbackQuery, payload *callbackdata.Payload) {
+	applicantID := payload.Target
+	lang := b.lang(callback.From)
+	status := models.VouchDenied
+	if payload.Arg(0) == "yes" {
+		status = models.VouchConfirmed
+	}
+
+	// Ответить можно только на запрос, адресованный именно этому соседу
+	ok, err := b.sheets.UpdateVouchStatus(b.ctx, applicantID, callback.From.ID, status)
+	if err != nil {
+		log.Printf("Error saving vouch: %v", err)
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "error.generic"))
+		b.send(msg)
+		return
+	}
+	if !ok {
+		msg := tgbotapi.NewMessage(callback.Message.Chat.ID, b.i18n.T(lang, "error.button_outdated"))
+		b.send(msg)
+		return
+	}
+
+	answer := b.i18n.T(lang, "vouch.thanks_confirmed")
+	verdict := "vouch.neighbor_confirmed"
+	if status != models.VouchConfirmed {
+		answer = b.i18n.T(lang, "vouch.thanks_denied")
+		verdict = "vouch.neighbor_denied"
+	}
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, answer)
+	b.send(editMsg)
+
+	applicant, err := b.getUser(applicantID)
+	if err != nil {
+		return
+	}
+
+	voucher := strings.TrimSpace(callback.From.FirstName + " " + callback.From.LastName)
+	text := b.i18n.T(b.langOf(b.config.AdminID), verdict,
+		voucher, applicant.FirstName, applicant.LastName, applicantID)
+	adminMsg := tgbotapi.NewMessage(b.config.AdminID, text)
+	b.send(adminMsg)
+
+	b.evaluateVouches(applicant)
+}
+
+// countVouches считает поручителей, удовлетворяющих правилам из конфигурации.
+// Каждый поручитель учитывается один раз по последнему ответу.
+func (b *Bot) countVouches(applicant *models.User) (confirmed, pending, denied int) {
+	vouches, err := b.sheets.GetVouches(b.ctx, applicant.TelegramID)
+	if err != nil {
+		log.Printf("Error loading vouches: %v", err)
+		return 0, 0, 0
+	}
+
+	latest := make(map[int64]*models.Vouch)
+	var order []int64
+	for _, vouch := range vouches {
+		prev, seen := latest[vouch.VoucherID]
+		if !seen {
+			order = append(order, vouch.VoucherID)
+		}
+		// Ожидающий запрос не перекрывает уже данный ответ
+		if !seen || vouch.Status != models.VouchPending || prev.Status == models.VouchPending {
+			latest[vouch.VoucherID] = vouch
+		}
+	}
+
+	settlement := models.Settlement(applicant.Address)
+	for _, voucherID := range order {
+		vouch := latest[voucherID]
+		switch vouch.Status {
+		case models.VouchPending:
+			pending++
+		case models.VouchDenied:
+			denied++
+		case models.VouchConfirmed:
+			voucher, err := b.getUser(vouch.VoucherID)
+			if err != nil || voucher.Status != models.StatusApproved {
+				continue
+			}
+			if b.config.Vouching.SameSettlement && models.Settlement(voucher.Address) != settlement {
+				continue
+			}
+			confirmed++
+		}
+	}
+	return confirmed, pending, denied
+}
+
+// evaluateVouches проверяет, набрала ли заявка нужное число подтверждений,
+// и одобряет ее автоматически или сообщает модератору
+func (b *Bot) evaluateVouches(applicant *models.User) {
+	// Правила из конфигурации тоже могут учитывать подтверждения
+	if b.applyRules(applicant) {
+		return
+	}
+
+	required := b.config.Vouching.Required
+	if required <= 0 {
+		required = defaultVouchRequired
+	}
+
+	confirmed, _, _ := b.countVouches(applicant)
+	if confirmed < required {
+		return
+	}
+
+	// Два ответа могут прийти одновременно: проверка и запись в журнал идут
+	// под блокировкой, чтобы заявку не одобрили и не отметили дважды
+	b.vouchMutex.Lock()
+	defer b.vouchMutex.Unlock()
+
+	user, err := b.getUser(applicant.TelegramID)
+	if err != nil || user.Status != models.StatusPending {
+		return
+	}
+	if evaluated, err := b.vouchesEvaluated(user.TelegramID); err != nil || evaluated {
+		return
+	}
+
+	decision := &rules.Decision{
+		Rule:    vouchRule,
+		Action:  rules.ActionFlag,
+		Comment: b.i18n.T(i18n.DefaultLanguage, "vouch.comment_flag", confirmed, required),
+	}
+	if b.config.Vouching.AutoApprove {
+		role := models.UserRole(b.config.Vouching.Role)
+		if !role.Valid() || role == models.RoleGuest {
+			role = models.RoleResident
+		}
+		decision.Action = rules.ActionApprove
+		decision.Role = role
+		decision.Comment = b.i18n.T(i18n.DefaultLanguage, "vouch.comment_approve", confirmed)
+	}
+	b.applyDecision(user, decision)
+}
+
+// vouchesEvaluated проверяет по журналу решений, обрабатывалась ли заявка
+// после набора подтверждений. Отмененное решение тоже считается: иначе
+// следующий ответ соседа одобрил бы заявку снова.
+func (b *Bot) vouchesEvaluated(userID int64) (bool, error) {
+	entries, err := b.sheets.GetAuditEntries(b.ctx, userID)
+	if err != nil {
+		log.Printf("Error loading audit entries: %v", err)
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.Rule == vouchRule {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// formatVouches формирует блок о подтверждениях для карточки модератора
+func (b *Bot) formatVouches(lang string, user *models.User) string {
+	confirmed, pending, denied := b.countVouches(user)
+	if confirmed+pending+denied == 0 {
+		return ""
+	}
+	return b.i18n.T(lang, "vouch.card", confirmed, pending, denied)
+}
@@ -0,0 +1,116 @@
+package bot
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+const (
+	defaultWebhookListen = ":8080"
+	webhookSecretHeader  = "X-Telegram-Bot-Api-Secret-Token"
+	updatesBuffer        = 100
+)
+
+// Telegram допускает в секрете только эти символы
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// receiveUpdates запускает прием обновлений в режиме из конфигурации.
+// Переход между режимами безопасен: перед long polling webhook снимается,
+// а при включении webhook Telegram сам перестает отдавать getUpdates.
+func (b *Bot) receiveUpdates() (tgbotapi.UpdatesChannel, error) {
+	if b.config.Webhook.URL == "" {
+		if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			return nil, fmt.Errorf("unable to delete webhook: %v", err)
+		}
+
+		u := tgbotapi.NewUpdate(0)
+		u.Timeout = 60
+		log.Println("Receiving updates via long polling")
+		return b.api.GetUpdatesChan(u), nil
+	}
+
+	return b.startWebhook()
+}
+
+// startWebhook поднимает HTTP сервер и регистрирует webhook в Telegram
+func (b *Bot) startWebhook() (tgbotapi.UpdatesChannel, error) {
+	cfg := b.config.Webhook
+
+	publicURL, err := url.Parse(cfg.URL)
+	if err != nil || publicURL.Scheme != "https" {
+		return nil, fmt.Errorf("webhook url must be an https URL, got %q", cfg.URL)
+	}
+	if !webhookSecretPattern.MatchString(cfg.SecretToken) {
+		return nil, errors.New("webhook secret_token is required: 1-256 characters A-Z, a-z, 0-9, _ and -")
+	}
+
+	path := cfg.Path
+	if path == "" {
+		path = publicURL.Path
+	}
+	if path == "" {
+		path = "/"
+	}
+	listen := cfg.Listen
+	if listen == "" {
+		listen = defaultWebhookListen
+	}
+
+	updates := make(chan tgbotapi.Update, updatesBuffer)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		secret := r.Header.Get(webhookSecretHeader)
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(cfg.SecretToken)) != 1 {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		update, err := b.api.HandleUpdate(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		updates <- *update
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		total, _ := b.dispatcher.depth()
+		fmt.Fprintf(w, "ok\nqueue_depth %d\nqueue_capacity %d\n", total, b.dispatcher.capacity())
+	})
+
+	// Слушаем порт до регистрации webhook, чтобы не потерять первые обновления
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on %s: %v", listen, err)
+	}
+
+	b.server = &http.Server{Handler: mux}
+	go func() {
+		if err := b.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("Webhook server error: %v", err)
+		}
+	}()
+
+	// tgbotapi v5.5.1 не знает secret_token, поэтому вызываем метод напрямую
+	_, err = b.api.MakeRequest("setWebhook", tgbotapi.Params{
+		"url":          publicURL.String(),
+		"secret_token": cfg.SecretToken,
+	})
+	if err != nil {
+		b.server.Close()
+		return nil, fmt.Errorf("unable to set webhook: %v", err)
+	}
+
+	log.Printf("Receiving updates via webhook %s (listening on %s%s)", publicURL, listen, path)
+	return updates, nil
+}
@@ -0,0 +1,147 @@
+// Package callbackdata кодирует данные inline кнопок: действие, объект,
+// аргументы и версию формата. Данные подписываются HMAC и имеют срок
+// действия, поэтому подделанные и устаревшие кнопки отклоняются.
+package callbackdata
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Version текущая версия формата; кнопки других версий считаются устаревшими
+	Version = 1
+
+	// MaxSize ограничение Telegram на размер callback_data в байтах
+	MaxSize = 64
+
+	// DefaultTTL срок действия кнопки, если не задан
+	DefaultTTL = 7 * 24 * time.Hour
+
+	separator    = ":"
+	argSeparator = ","
+	signatureLen = 12 // байт HMAC-SHA256, 16 символов base64
+)
+
+var (
+	ErrMalformed = errors.New("malformed callback data")
+	ErrSignature = errors.New("invalid callback signature")
+	ErrVersion   = errors.New("unsupported callback version")
+	ErrExpired   = errors.New("callback expired")
+	ErrTooLong   = errors.New("callback data exceeds 64 bytes")
+)
+
+// Payload данные inline кнопки
+type Payload struct {
+	Action  string
+	Target  int64 // пользователь, к которому относится кнопка, 0 — нет
+	Args    []string
+	Version int
+	Expires time.Time
+}
+
+// Arg возвращает i-й аргумент или пустую строку
+func (p *Payload) Arg(i int) string {
+	if i < 0 || i >= len(p.Args) {
+		return ""
+	}
+	return p.Args[i]
+}
+
+// Codec подписывает и проверяет данные кнопок
+type Codec struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// New создает кодек с ключом подписи secret и сроком действия кнопок ttl
+func New(secret string, ttl time.Duration) *Codec {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Codec{key: []byte(secret), ttl: ttl, now: time.Now}
+}
+
+// Encode кодирует кнопку действия action для пользователя target
+func (c *Codec) Encode(action string, target int64, args ...string) (string, error) {
+	if action == "" || strings.ContainsAny(action, separator+argSeparator) {
+		return "", fmt.Errorf("invalid action %q", action)
+	}
+	for _, arg := range args {
+		if strings.ContainsAny(arg, separator+argSeparator) {
+			return "", fmt.Errorf("invalid argument %q", arg)
+		}
+	}
+
+	expires := c.now().Add(c.ttl).Unix()
+	body := strings.Join([]string{
+		action,
+		strconv.Itoa(Version),
+		strconv.FormatInt(target, 36),
+		strings.Join(args, argSeparator),
+		strconv.FormatInt(expires, 36),
+	}, separator)
+
+	data := body + separator + c.sign(body)
+	if len(data) > MaxSize {
+		return "", fmt.Errorf("%w: %s (%d bytes)", ErrTooLong, action, len(data))
+	}
+	return data, nil
+}
+
+// Decode проверяет подпись, версию и срок действия и возвращает данные кнопки
+func (c *Codec) Decode(data string) (*Payload, error) {
+	fields := strings.Split(data, separator)
+	if len(fields) != 6 {
+		return nil, ErrMalformed
+	}
+
+	body := strings.Join(fields[:5], separator)
+	if !hmac.Equal([]byte(fields[5]), []byte(c.sign(body))) {
+		return nil, ErrSignature
+	}
+
+	version, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if version != Version {
+		return nil, ErrVersion
+	}
+
+	target, err := strconv.ParseInt(fields[2], 36, 64)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	expires, err := strconv.ParseInt(fields[4], 36, 64)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	payload := &Payload{
+		Action:  fields[0],
+		Target:  target,
+		Version: version,
+		Expires: time.Unix(expires, 0),
+	}
+	if fields[3] != "" {
+		payload.Args = strings.Split(fields[3], argSeparator)
+	}
+	if c.now().After(payload.Expires) {
+		return payload, ErrExpired
+	}
+	return payload, nil
+}
+
+func (c *Codec) sign(body string) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(body))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:signatureLen])
+}
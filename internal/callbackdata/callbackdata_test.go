@@ -0,0 +1,118 @@
+package callbackdata
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestCodec(now time.Time) *Codec {
+	c := New("test-secret", time.Hour)
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestRoundTrip(t *testing.T) {
+	c := newTestCodec(time.Unix(1700000000, 0))
+
+	data, err := c.Encode("approve", 123456789, "житель")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	payload, err := c.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if payload.Action != "approve" || payload.Target != 123456789 || payload.Arg(0) != "житель" || payload.Arg(1) != "" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+}
+
+func TestTampered(t *testing.T) {
+	c := newTestCodec(time.Unix(1700000000, 0))
+	data, err := c.Encode("approve", 42, "житель")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	fields := strings.Split(data, separator)
+
+	replace := func(i int, value string) string {
+		changed := append([]string(nil), fields...)
+		changed[i] = value
+		return strings.Join(changed, separator)
+	}
+
+	cases := map[string]string{
+		"action":    replace(0, "reject"),
+		"target":    replace(2, "43"),
+		"args":      replace(3, "ОК"),
+		"expires":   replace(4, "zzzzzz"),
+		"signature": replace(5, strings.Repeat("A", len(fields[5]))),
+	}
+	for name, tampered := range cases {
+		if _, err := c.Decode(tampered); !errors.Is(err, ErrSignature) {
+			t.Errorf("%s: expected ErrSignature, got %v", name, err)
+		}
+	}
+
+	other := New("other-secret", time.Hour)
+	if _, err := other.Decode(data); !errors.Is(err, ErrSignature) {
+		t.Errorf("other key: expected ErrSignature, got %v", err)
+	}
+
+	if _, err := c.Decode("approve:1:16"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("short data: expected ErrMalformed, got %v", err)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	issued := time.Unix(1700000000, 0)
+	data, err := newTestCodec(issued).Encode("reject", 42)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	if _, err := newTestCodec(issued.Add(time.Hour)).Decode(data); err != nil {
+		t.Errorf("at expiry: expected valid, got %v", err)
+	}
+
+	payload, err := newTestCodec(issued.Add(time.Hour + time.Second)).Decode(data)
+	if !errors.Is(err, ErrExpired) {
+		t.Fatalf("after expiry: expected ErrExpired, got %v", err)
+	}
+	// Подпись проверена, поэтому данные истекшей кнопки можно использовать
+	if payload == nil || payload.Action != "reject" || payload.Target != 42 {
+		t.Errorf("after expiry: unexpected payload %+v", payload)
+	}
+}
+
+func TestMaxSize(t *testing.T) {
+	c := newTestCodec(time.Unix(1700000000, 0))
+
+	// Самые длинные кнопки бота: ID пользователя Telegram и роль кириллицей
+	data, err := c.Encode("approve", 9999999999, "житель")
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if len(data) > MaxSize {
+		t.Errorf("encoded %d bytes, limit %d", len(data), MaxSize)
+	}
+
+	_, err = c.Encode("approve", 9999999999, strings.Repeat("x", MaxSize))
+	if !errors.Is(err, ErrTooLong) {
+		t.Errorf("expected ErrTooLong, got %v", err)
+	}
+}
+
+func TestInvalidArguments(t *testing.T) {
+	c := newTestCodec(time.Unix(1700000000, 0))
+	for _, action := range []string{"", "a:b", "a,b"} {
+		if _, err := c.Encode(action, 1); err == nil {
+			t.Errorf("action %q: expected error", action)
+		}
+	}
+	if _, err := c.Encode("bulk", 0, "a,b"); err == nil {
+		t.Error("argument with separator: expected error")
+	}
+}
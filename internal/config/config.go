@@ -12,6 +12,142 @@ type Config struct {
 	AdminID         int64  `json:"admin_id"`
 	SpreadsheetID   string `json:"spreadsheet_id"`
 	CredentialsPath string `json:"credentials_path"`
+	// UsersSheet имя вкладки с пользователями, пусто — первая вкладка
+	UsersSheet string `json:"users_sheet"`
+
+	// Ограничение анонимной переписки между соседями: не больше
+	// RelayLimit сообщений одному адресату за RelayWindowMinutes
+	RelayLimit         int `json:"relay_limit"`
+	RelayWindowMinutes int `json:"relay_window_minutes"`
+
+	// Ограничение запросов от одного пользователя: не больше RateLimit
+	// команд, нажатий и сообщений за RateWindowSeconds; модераторов не касается
+	RateLimit         int `json:"rate_limit"`
+	RateWindowSeconds int `json:"rate_window_seconds"`
+
+	// Сколько раз отправлять сообщение в Telegram при временных ошибках;
+	// ожидание по retry_after в попытки входит
+	SendMaxAttempts int `json:"send_max_attempts"`
+
+	// Скорость рассылки /broadcast, сообщений в секунду, по умолчанию 20
+	BroadcastPerSecond int `json:"broadcast_per_second"`
+
+	// Кэш таблицы пользователей: время жизни и период сверки с таблицей
+	CacheTTLSeconds     int `json:"cache_ttl_seconds"`
+	SyncIntervalSeconds int `json:"sync_interval_seconds"`
+
+	// Повторы запросов к Google Sheets и клиентское ограничение частоты
+	SheetsMaxAttempts       int `json:"sheets_max_attempts"`
+	SheetsRequestsPerMinute int `json:"sheets_requests_per_minute"`
+
+	// Локальный журнал записей, которые еще не попали в Google Sheets
+	OutboxPath string `json:"outbox_path"`
+
+	// Правила подтверждения заявок соседями
+	Vouching VouchingConfig `json:"vouching"`
+
+	// Правила автоматической обработки заявок, проверяются по порядку
+	Rules []RuleConfig `json:"rules"`
+
+	// Обработка обновлений: число обработчиков и размер очереди каждого
+	Workers   int `json:"workers"`
+	QueueSize int `json:"queue_size"`
+
+	// Остановка: сколько ждать обработчики и куда сохранить черновики регистрации
+	ShutdownTimeoutSeconds int    `json:"shutdown_timeout_seconds"`
+	DraftsPath             string `json:"drafts_path"`
+
+	// Режим получения обновлений: без webhook.url — long polling
+	Webhook WebhookConfig `json:"webhook"`
+
+	// Подпись данных inline кнопок: ключ (пусто — выводится из токена бота)
+	// и срок действия кнопок, по умолчанию 168 часов
+	CallbackSecret   string `json:"callback_secret"`
+	CallbackTTLHours int    `json:"callback_ttl_hours"`
+
+	// Шаблоны сообщений text/template: ключ — название шаблона (welcome,
+	// registered, approved, rejected), для одного языка — с суффиксом ".en".
+	// Шаблоны, измененные командой /templates, имеют приоритет.
+	Templates map[string]string `json:"templates"`
+
+	// Напоминания о заявках, которые долго ждут решения
+	Reminders ReminderConfig `json:"reminders"`
+
+	// Дополнительные модераторы и их доступ к персональным данным.
+	// AdminID всегда имеет полный доступ.
+	Moderators []Moderator `json:"moderators"`
+}
+
+// WebhookConfig настройки приема обновлений через webhook
+type WebhookConfig struct {
+	URL         string `json:"url"`          // публичный HTTPS адрес, который вызывает Telegram
+	Listen      string `json:"listen"`       // адрес HTTP сервера, по умолчанию :8080
+	Path        string `json:"path"`         // путь обработчика, по умолчанию путь из url
+	SecretToken string `json:"secret_token"` // проверяется в заголовке каждого запроса
+}
+
+// Уровни доступа модератора к персональным данным
+const (
+	AccessFull    = "full"    // телефоны, email и комментарии
+	AccessLimited = "limited" // без телефонов, email и комментариев
+)
+
+// Moderator дополнительный модератор
+type Moderator struct {
+	ID     int64  `json:"id"`
+	Access string `json:"access"` // full или limited, по умолчанию limited
+}
+
+// Access возвращает уровень доступа пользователя и false, если он не модератор
+func (c *Config) Access(userID int64) (string, bool) {
+	if userID == c.AdminID {
+		return AccessFull, true
+	}
+	for _, moderator := range c.Moderators {
+		if moderator.ID != userID {
+			continue
+		}
+		if moderator.Access == AccessFull {
+			return AccessFull, true
+		}
+		return AccessLimited, true
+	}
+	return "", false
+}
+
+// ReminderConfig расписание напоминаний о заявках в статусе pending
+type ReminderConfig struct {
+	DigestTime         string `json:"digest_time"`          // время ежедневной сводки модераторам, "09:00"; пусто — напоминаний нет
+	PendingHours       int    `json:"pending_hours"`        // в сводку попадают заявки старше, по умолчанию 48 часов
+	NotifyApplicants   bool   `json:"notify_applicants"`    // напоминать заявителю, что заявка в очереди
+	ApplicantEveryDays int    `json:"applicant_every_days"` // не чаще чем раз в столько дней, по умолчанию 7
+}
+
+// VouchingConfig задает, сколько подтверждений от одобренных соседей
+// достаточно для заявки и что с ней делать дальше
+type VouchingConfig struct {
+	Required       int    `json:"required"`        // по умолчанию 2
+	SameSettlement bool   `json:"same_settlement"` // учитывать только соседей из того же поселка
+	AutoApprove    bool   `json:"auto_approve"`    // одобрять без модератора
+	Role           string `json:"role"`            // роль при автоодобрении, по умолчанию житель
+}
+
+// RuleConfig правило автоматической обработки заявки. Правило срабатывает,
+// если выполнены все заданные условия.
+type RuleConfig struct {
+	Name string `json:"name"`
+
+	// Условия
+	AddressPattern string `json:"address_pattern"` // регулярное выражение для адреса вида "GFC P11"
+	PhoneAllowlist bool   `json:"phone_allowlist"` // телефон есть во вкладке Allowlist
+	MinVouches     int    `json:"min_vouches"`     // не меньше подтверждений соседей
+	RegistryPhone  bool   `json:"registry_phone"`  // телефон совпал с собственником участка по реестру
+	RegistryName   bool   `json:"registry_name"`   // имя совпало с собственником участка по реестру
+
+	// Действие: approve, flag или reject
+	Action  string `json:"action"`
+	Role    string `json:"role"`    // роль при approve, по умолчанию житель
+	Comment string `json:"comment"` // комментарий в таблице и причина для заявителя
 }
 
 // LoadConfig загружает конфигурацию из файла или переменных окружения
@@ -38,6 +174,13 @@ func LoadConfig(path string) (*Config, error) {
 			AdminID:         adminID,
 			SpreadsheetID:   spreadsheetID,
 			CredentialsPath: credentialsPath,
+			Webhook: WebhookConfig{
+				URL:         os.Getenv("WEBHOOK_URL"),
+				Listen:      os.Getenv("WEBHOOK_LISTEN"),
+				Path:        os.Getenv("WEBHOOK_PATH"),
+				SecretToken: os.Getenv("WEBHOOK_SECRET_TOKEN"),
+			},
+			CallbackSecret: os.Getenv("CALLBACK_SECRET"),
 		}, nil
 	}
 
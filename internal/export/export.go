@@ -0,0 +1,244 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram_verification_bot/internal/models"
+)
+
+// Format формат выгрузки
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+const dateLayout = "2006-01-02"
+
+// Filter отбор пользователей для выгрузки. Пустые поля не ограничивают выборку.
+type Filter struct {
+	Status models.UserStatus
+	Role   models.UserRole
+	From   time.Time // дата регистрации не раньше
+	To     time.Time // дата регистрации не позже (включительно)
+	// Settlement код поселка из адреса. При SettlementPrefix это начало
+	// кода: "GF" подходит для GFC и GFP.
+	Settlement       string
+	SettlementPrefix bool
+}
+
+// Match проверяет пользователя по фильтру
+func (f Filter) Match(user *models.User) bool {
+	if f.Status != "" && user.Status != f.Status {
+		return false
+	}
+	if f.Role != "" && user.Role != f.Role {
+		return false
+	}
+	if f.Settlement != "" && !f.matchSettlement(models.Settlement(user.Address)) {
+		return false
+	}
+	if !f.From.IsZero() && user.RegisterDate.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !user.RegisterDate.Before(f.To.AddDate(0, 0, 1)) {
+		return false
+	}
+	return true
+}
+
+func (f Filter) matchSettlement(settlement string) bool {
+	if f.SettlementPrefix {
+		return strings.HasPrefix(settlement, f.Settlement)
+	}
+	return settlement == f.Settlement
+}
+
+// ParseFilter разбирает аргументы вида status=approved role=житель
+// settlement=GFC from=2024-01-01 to=2024-12-31. Аргумент csv или xlsx задает
+// формат. Код поселка со звездочкой в конце (settlement=GF*) задает начало кода.
+func ParseFilter(args []string) (Filter, Format, error) {
+	var filter Filter
+	format := FormatCSV
+
+	for _, arg := range args {
+		key, value, found := strings.Cut(arg, "=")
+		if !found {
+			switch Format(strings.ToLower(arg)) {
+			case FormatCSV, FormatXLSX:
+				format = Format(strings.ToLower(arg))
+				continue
+			}
+			return filter, format, fmt.Errorf("непонятный аргумент %q", arg)
+		}
+
+		var err error
+		switch strings.ToLower(key) {
+		case "status":
+			filter.Status = models.UserStatus(value)
+			if !filter.Status.Valid() {
+				err = fmt.Errorf("неизвестный статус %q", value)
+			}
+		case "role":
+			filter.Role = models.UserRole(value)
+			if !filter.Role.Valid() {
+				err = fmt.Errorf("неизвестная роль %q", value)
+			}
+		case "settlement":
+			code, prefix := strings.CutSuffix(value, "*")
+			filter.Settlement = models.NormalizeAddress(code)
+			filter.SettlementPrefix = prefix
+			if filter.Settlement == "" || strings.ContainsAny(filter.Settlement, " *") {
+				err = fmt.Errorf("неверный код поселка %q", value)
+			}
+		case "from":
+			filter.From, err = time.ParseInLocation(dateLayout, value, time.Local)
+		case "to":
+			filter.To, err = time.ParseInLocation(dateLayout, value, time.Local)
+		default:
+			err = fmt.Errorf("неизвестный фильтр %q", key)
+		}
+		if err != nil {
+			return filter, format, err
+		}
+	}
+	return filter, format, nil
+}
+
+// Apply возвращает пользователей, подходящих под фильтр
+func Apply(users []*models.User, filter Filter) []*models.User {
+	var result []*models.User
+	for _, user := range users {
+		if filter.Match(user) {
+			result = append(result, user)
+		}
+	}
+	return result
+}
+
+// Поля выгрузки
+var (
+	allFields = []string{
+		"User ID", "Username", "Имя", "Фамилия", "Телефон", "Email", "Адрес",
+		"Дата регистрации", "Статус", "Роль", "Админ комментарий", "Дата обновления", "Кем обновлено",
+	}
+	personalFields = map[string]bool{"Телефон": true, "Email": true, "Админ комментарий": true}
+)
+
+// Fields возвращает поля выгрузки. Без доступа к персональным данным
+// телефон, email и комментарий модератора не выгружаются.
+func Fields(personal bool) []string {
+	var fields []string
+	for _, field := range allFields {
+		if personal || !personalFields[field] {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func value(user *models.User, field string) string {
+	switch field {
+	case "User ID":
+		return strconv.FormatInt(user.TelegramID, 10)
+	case "Username":
+		return user.Username
+	case "Имя":
+		return user.FirstName
+	case "Фамилия":
+		return user.LastName
+	case "Телефон":
+		return user.Phone
+	case "Email":
+		return user.Email
+	case "Адрес":
+		return user.Address
+	case "Дата регистрации":
+		return formatTime(user.RegisterDate)
+	case "Статус":
+		return string(user.Status)
+	case "Роль":
+		return string(user.Role)
+	case "Админ комментарий":
+		return user.AdminComment
+	case "Дата обновления":
+		return formatTime(user.UpdatedAt)
+	case "Кем обновлено":
+		return user.UpdatedBy
+	}
+	return ""
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func rows(users []*models.User, fields []string) [][]string {
+	result := [][]string{fields}
+	for _, user := range users {
+		row := make([]string, len(fields))
+		for i, field := range fields {
+			row[i] = value(user, field)
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
+// phonePattern номер телефона: плюс, затем только цифры, пробелы и дефисы
+var phonePattern = regexp.MustCompile(`^\+[0-9 -]+$`)
+
+// escapeFormula экранирует значения, которые Excel и Google Таблицы приняли
+// бы за формулу при открытии CSV: пользователь мог ввести в имя или адрес
+// "=HYPERLINK(...)". Номера телефонов формулой не считаются и не меняются.
+func escapeFormula(s string) string {
+	if s == "" || phonePattern.MatchString(s) {
+		return s
+	}
+	if strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Write записывает выгрузку в выбранном формате
+func Write(w io.Writer, format Format, users []*models.User, fields []string) error {
+	switch format {
+	case FormatXLSX:
+		return writeXLSX(w, rows(users, fields))
+	default:
+		return writeCSV(w, rows(users, fields))
+	}
+}
+
+// writeCSV записывает CSV. В XLSX ячейки хранятся как строки и формулой не
+// становятся, поэтому экранирование нужно только здесь.
+func writeCSV(w io.Writer, records [][]string) error {
+	escaped := make([][]string, len(records))
+	for i, record := range records {
+		escaped[i] = make([]string, len(record))
+		for j, cell := range record {
+			escaped[i][j] = escapeFormula(cell)
+		}
+	}
+
+	// BOM, чтобы Excel открыл кириллицу в UTF-8
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(escaped); err != nil {
+		return err
+	}
+	return writer.Error()
+}
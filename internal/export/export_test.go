@@ -0,0 +1,180 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"telegram_verification_bot/internal/models"
+)
+
+func testUsers() []*models.User {
+	return []*models.User{{
+		TelegramID: 42,
+		FirstName:  "=HYPERLINK(\"http://example.com\")",
+		LastName:   "-Иванов",
+		Phone:      "+7 900 123-45-67",
+		Email:      "+cmd|calc",
+		Address:    "GFC 12",
+	}}
+}
+
+var exportFields = []string{"Имя", "Фамилия", "Телефон", "Email", "Адрес"}
+
+func TestWriteCSVEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, testUsers(), exportFields); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected header and one row, got %d records", len(records))
+	}
+
+	want := []string{
+		"'=HYPERLINK(\"http://example.com\")",
+		"'-Иванов",
+		"+7 900 123-45-67", // телефон не экранируется
+		"'+cmd|calc",
+		"GFC 12",
+	}
+	for i, cell := range records[1] {
+		if cell != want[i] {
+			t.Errorf("%s: got %q, want %q", exportFields[i], cell, want[i])
+		}
+	}
+}
+
+func TestWriteXLSXKeepsValues(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatXLSX, testUsers(), exportFields); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+	file, err := archive.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatalf("open sheet: %v", err)
+	}
+	defer file.Close()
+
+	var sheet struct {
+		Rows []struct {
+			Cells []struct {
+				Type string `xml:"t,attr"`
+				Text string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("read sheet: %v", err)
+	}
+	if err := xml.Unmarshal(data, &sheet); err != nil {
+		t.Fatalf("xml: %v", err)
+	}
+	if len(sheet.Rows) != 2 {
+		t.Fatalf("expected header and one row, got %d rows", len(sheet.Rows))
+	}
+
+	// Строковые ячейки не вычисляются, поэтому значения остаются как есть
+	user := testUsers()[0]
+	want := []string{user.FirstName, user.LastName, user.Phone, user.Email, user.Address}
+	for i, cell := range sheet.Rows[1].Cells {
+		if cell.Type != "inlineStr" {
+			t.Errorf("%s: cell type %q, want inlineStr", exportFields[i], cell.Type)
+		}
+		if cell.Text != want[i] {
+			t.Errorf("%s: got %q, want %q", exportFields[i], cell.Text, want[i])
+		}
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	cases := map[string]string{
+		"":                 "",
+		"Иван":             "Иван",
+		"=1+1":             "'=1+1",
+		"@SUM(A1)":         "'@SUM(A1)",
+		"-5":               "'-5",
+		"\tTAB":            "'\tTAB",
+		"+79001234567":     "+79001234567",
+		"+7 900 123-45-67": "+7 900 123-45-67",
+		"+7(900)1234567":   "'+7(900)1234567",
+		"+":                "'+",
+	}
+	for in, want := range cases {
+		if got := escapeFormula(in); got != want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		args    []string
+		filter  Filter
+		format  Format
+		wantErr bool
+	}{
+		{nil, Filter{}, FormatCSV, false},
+		{[]string{"XLSX", "status=approved", "role=житель"}, Filter{Status: models.StatusApproved, Role: models.RoleResident}, FormatXLSX, false},
+		{[]string{"settlement=gfc"}, Filter{Settlement: "GFC"}, FormatCSV, false},
+		{[]string{"settlement=gf*"}, Filter{Settlement: "GF", SettlementPrefix: true}, FormatCSV, false},
+		{[]string{"settlement=*"}, Filter{}, FormatCSV, true},
+		{[]string{"settlement=gfc p11"}, Filter{}, FormatCSV, true},
+		{[]string{"status=banned"}, Filter{}, FormatCSV, true},
+		{[]string{"role=админ"}, Filter{}, FormatCSV, true},
+		{[]string{"from=2024-13-01"}, Filter{}, FormatCSV, true},
+		{[]string{"color=red"}, Filter{}, FormatCSV, true},
+		{[]string{"pdf"}, Filter{}, FormatCSV, true},
+	}
+	for _, tt := range tests {
+		filter, format, err := ParseFilter(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseFilter(%q): err = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (filter != tt.filter || format != tt.format) {
+			t.Errorf("ParseFilter(%q) = %+v, %s, want %+v, %s", tt.args, filter, format, tt.filter, tt.format)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	registered := time.Date(2024, 3, 10, 18, 0, 0, 0, time.Local)
+	user := &models.User{Status: models.StatusApproved, Role: models.RoleResident, Address: "GFC P11", RegisterDate: registered}
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.Local) }
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"status", Filter{Status: models.StatusPending}, false},
+		{"settlement", Filter{Settlement: "GFC"}, true},
+		{"settlement exact", Filter{Settlement: "GF"}, false},
+		{"settlement prefix", Filter{Settlement: "GF", SettlementPrefix: true}, true},
+		{"to is inclusive", Filter{To: day(10)}, true},
+		{"before from", Filter{From: day(11)}, false},
+		{"after to", Filter{To: day(9)}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(user); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
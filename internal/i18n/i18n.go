@@ -0,0 +1,183 @@
+// Package i18n хранит тексты бота на нескольких языках. Тексты лежат в
+// файлах locales/<язык>.json: ключ — строка для fmt.Sprintf или объект
+// с формами множественного числа (one, few, many, other).
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage язык по умолчанию и язык, в котором ищутся недостающие тексты
+const DefaultLanguage = "ru"
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// entry текст или формы множественного числа
+type entry struct {
+	text   string
+	plural map[string]string
+}
+
+func (e *entry) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &e.text); err == nil {
+		return nil
+	}
+	return json.Unmarshal(data, &e.plural)
+}
+
+// form возвращает форму множественного числа; в языках без формы other
+// (русский) ее заменяет many
+func (e *entry) form(name string) string {
+	if text, ok := e.plural[name]; ok {
+		return text
+	}
+	if text, ok := e.plural["other"]; ok {
+		return text
+	}
+	return e.plural["many"]
+}
+
+// Catalog тексты всех языков
+type Catalog struct {
+	messages map[string]map[string]*entry
+}
+
+// Load загружает встроенные файлы языков и проверяет, что в каждом есть все
+// тексты языка по умолчанию
+func Load() (*Catalog, error) {
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{messages: make(map[string]map[string]*entry)}
+	for _, file := range files {
+		data, err := localeFiles.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]*entry
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("locale %s: %v", file.Name(), err)
+		}
+		c.messages[strings.TrimSuffix(file.Name(), ".json")] = messages
+	}
+
+	base, ok := c.messages[DefaultLanguage]
+	if !ok {
+		return nil, fmt.Errorf("locale %s.json not found", DefaultLanguage)
+	}
+	for lang, messages := range c.messages {
+		var missing []string
+		for key := range base {
+			if _, ok := messages[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return nil, fmt.Errorf("locale %s: missing %s", lang, strings.Join(missing, ", "))
+		}
+	}
+	return c, nil
+}
+
+// Languages возвращает коды доступных языков по алфавиту
+func (c *Catalog) Languages() []string {
+	var langs []string
+	for lang := range c.messages {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Resolve сводит код языка Telegram ("en-US", "ru") к доступному языку
+func (c *Catalog) Resolve(code string) string {
+	code = strings.ToLower(code)
+	code, _, _ = strings.Cut(code, "-")
+	if _, ok := c.messages[code]; ok {
+		return code
+	}
+	return DefaultLanguage
+}
+
+// lookup ищет текст в языке lang, затем в языке по умолчанию
+func (c *Catalog) lookup(lang, key string) *entry {
+	if e, ok := c.messages[lang][key]; ok {
+		return e
+	}
+	return c.messages[DefaultLanguage][key]
+}
+
+// T возвращает текст key на языке lang, подставляя args
+func (c *Catalog) T(lang, key string, args ...interface{}) string {
+	e := c.lookup(lang, key)
+	if e == nil {
+		return key
+	}
+	text := e.text
+	if e.plural != nil {
+		text = e.form("other")
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// N возвращает форму текста key для числа n; n подставляется первым аргументом
+func (c *Catalog) N(lang, key string, n int, args ...interface{}) string {
+	e := c.lookup(lang, key)
+	if e == nil {
+		return key
+	}
+	text := e.text
+	if e.plural != nil {
+		text = e.form(pluralForm(lang, n))
+	}
+	return fmt.Sprintf(text, append([]interface{}{n}, args...)...)
+}
+
+// Match ищет среди текстов с префиксом prefix тот, что совпадает с text
+// на любом языке, и возвращает его ключ. Так кнопки меню распознаются
+// независимо от языка, на котором их показали.
+func (c *Catalog) Match(prefix, text string) (string, bool) {
+	for _, messages := range c.messages {
+		for key, e := range messages {
+			if strings.HasPrefix(key, prefix) && e.plural == nil && e.text == text {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// pluralForm выбирает форму множественного числа по правилам CLDR
+func pluralForm(lang string, n int) string {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case "ru", "uk", "be":
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return "one"
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return "few"
+		default:
+			return "many"
+		}
+	default:
+		if n == 1 {
+			return "one"
+		}
+		return "other"
+	}
+}
@@ -0,0 +1,45 @@
+package i18n
+
+import "testing"
+
+func TestPluralForm(t *testing.T) {
+	tests := []struct {
+		lang string
+		n    int
+		want string
+	}{
+		{"ru", 0, "many"},
+		{"ru", 1, "one"},
+		{"ru", 2, "few"},
+		{"ru", 4, "few"},
+		{"ru", 5, "many"},
+		{"ru", 11, "many"},
+		{"ru", 12, "many"},
+		{"ru", 14, "many"},
+		{"ru", 21, "one"},
+		{"ru", 22, "few"},
+		{"ru", 111, "many"},
+		{"ru", 101, "one"},
+		{"ru", -3, "few"},
+		{"uk", 23, "few"},
+		{"en", 0, "other"},
+		{"en", 1, "one"},
+		{"en", 2, "other"},
+		{"en", 21, "other"},
+	}
+	for _, tt := range tests {
+		if got := pluralForm(tt.lang, tt.n); got != tt.want {
+			t.Errorf("pluralForm(%q, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	catalog, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if catalog.Resolve("en-US") != "en" || catalog.Resolve("xx") != DefaultLanguage {
+		t.Errorf("Resolve: en-US -> %q, xx -> %q", catalog.Resolve("en-US"), catalog.Resolve("xx"))
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// AuditEntry запись журнала автоматических решений по заявкам
+type AuditEntry struct {
+	ID         string
+	CreatedAt  time.Time
+	TelegramID int64
+	Rule       string
+	Action     string // approve, flag или reject
+	Role       UserRole
+	Comment    string
+	RevertedBy string // кто отменил решение, пусто — решение в силе
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// DocumentKind тип загруженного файла
+type DocumentKind string
+
+const (
+	DocumentPhoto DocumentKind = "фото"
+	DocumentFile  DocumentKind = "файл"
+)
+
+// Document документ, подтверждающий право собственности или аренды.
+// Сам файл хранится в Telegram, бот помнит только его file_id.
+type Document struct {
+	ApplicantID    int64
+	FileID         string
+	Kind           DocumentKind
+	FileName       string
+	MimeType       string
+	Size           int
+	UploadedAt     time.Time
+	AdminMessageID int // копия у модератора, удаляется вместе с записью
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// Household домохозяйство: жители одного участка
+type Household struct {
+	Plot    string // нормализованный адрес участка, например "GFC P11"
+	OwnerID int64  // основной владелец, 0 если не назначен
+	Members []*User
+}
+
+// GroupHouseholds группирует пользователей по участкам в порядке первого
+// появления участка в списке. owners — основные владельцы участков.
+func GroupHouseholds(users []*User, owners map[string]int64) []*Household {
+	var households []*Household
+	byPlot := make(map[string]*Household)
+
+	for _, user := range users {
+		plot := NormalizeAddress(user.Address)
+		household, ok := byPlot[plot]
+		if !ok {
+			household = &Household{Plot: plot, OwnerID: owners[plot]}
+			byPlot[plot] = household
+			households = append(households, household)
+		}
+		household.Members = append(household.Members, user)
+	}
+
+	// Владелец показывается первым
+	for _, household := range households {
+		ownerID := household.OwnerID
+		sort.SliceStable(household.Members, func(i, j int) bool {
+			return household.Members[i].TelegramID == ownerID && household.Members[j].TelegramID != ownerID
+		})
+	}
+
+	return households
+}
+
+// VouchKind определяет, кто подтверждает заявку
+type VouchKind string
+
+const (
+	VouchOwner    VouchKind = "владелец"
+	VouchNeighbor VouchKind = "сосед"
+)
+
+// VouchStatus состояние запроса подтверждения
+type VouchStatus string
+
+const (
+	VouchPending   VouchStatus = "ожидает"
+	VouchConfirmed VouchStatus = "да"
+	VouchDenied    VouchStatus = "нет"
+)
+
+// Vouch подтверждение заявки другим жителем
+type Vouch struct {
+	ApplicantID int64
+	VoucherID   int64
+	Kind        VouchKind
+	Status      VouchStatus
+	CreatedAt   time.Time
+}
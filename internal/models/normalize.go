@@ -0,0 +1,60 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizePhone приводит телефон к цифрам в формате 7XXXXXXXXXX,
+// чтобы "+7 (912) 345-67-89" и "89123456789" считались одним номером
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		}
+	}
+
+	result := digits.String()
+	if len(result) == 11 && result[0] == '8' {
+		result = "7" + result[1:]
+	}
+	if len(result) == 10 {
+		result = "7" + result
+	}
+	return result
+}
+
+// NormalizeEmail приводит email к нижнему регистру без пробелов
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeName приводит имя к нижнему регистру, заменяет ё на е и
+// схлопывает пробелы
+func NormalizeName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, "ё", "е")
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// NormalizeAddress приводит адрес к виду "GFC P11": верхний регистр,
+// знаки препинания заменяются пробелами
+func NormalizeAddress(address string) string {
+	address = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return ' '
+	}, address)
+	return strings.Join(strings.Fields(address), " ")
+}
+
+// Settlement возвращает код поселка из адреса: "GFC P11" -> "GFC"
+func Settlement(address string) string {
+	fields := strings.Fields(NormalizeAddress(address))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
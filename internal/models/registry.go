@@ -0,0 +1,70 @@
+package models
+
+import "strings"
+
+// RegistryEntry строка реестра собственников участков
+type RegistryEntry struct {
+	Plot  string // нормализованный адрес участка
+	Owner string // ФИО собственника
+	Phone string // телефоны как в реестре, может быть несколько через запятую
+}
+
+// RegistryMatch результат сверки заявки с реестром
+type RegistryMatch struct {
+	Owners     []RegistryEntry // собственники заявленного участка
+	PhoneMatch bool            // телефон заявителя совпал с телефоном собственника
+	NameMatch  bool            // имя и фамилия заявителя совпали с собственником
+}
+
+// MatchRegistry сверяет заявителя с собственниками заявленного участка
+func MatchRegistry(entries []RegistryEntry, user *User) RegistryMatch {
+	var match RegistryMatch
+	plot := NormalizeAddress(user.Address)
+	phone := NormalizePhone(user.Phone)
+
+	for _, entry := range entries {
+		if entry.Plot != plot {
+			continue
+		}
+		match.Owners = append(match.Owners, entry)
+
+		for _, ownerPhone := range strings.FieldsFunc(entry.Phone, func(r rune) bool {
+			return r == ',' || r == ';' || r == '/'
+		}) {
+			if phone != "" && NormalizePhone(ownerPhone) == phone {
+				match.PhoneMatch = true
+			}
+		}
+		if nameMatches(entry.Owner, user.FirstName, user.LastName) {
+			match.NameMatch = true
+		}
+	}
+	return match
+}
+
+// nameMatches проверяет, что в ФИО собственника есть фамилия заявителя и его
+// имя целиком или инициалом: "Иванов Иван Иванович", "Иванов И.И."
+func nameMatches(owner, firstName, lastName string) bool {
+	first := NormalizeName(firstName)
+	last := NormalizeName(lastName)
+	if first == "" || last == "" {
+		return false
+	}
+
+	tokens := strings.FieldsFunc(NormalizeName(owner), func(r rune) bool {
+		return r == ' ' || r == '.' || r == ','
+	})
+
+	hasLast, hasFirst := false, false
+	for _, token := range tokens {
+		switch {
+		case token == last:
+			hasLast = true
+		case token == first:
+			hasFirst = true
+		case len([]rune(token)) == 1 && []rune(token)[0] == []rune(first)[0]:
+			hasFirst = true
+		}
+	}
+	return hasLast && hasFirst
+}
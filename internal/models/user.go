@@ -12,6 +12,19 @@ const (
 	RoleOK       UserRole = "ОК"
 )
 
+// Roles все допустимые роли
+var Roles = []UserRole{RoleGuest, RoleResident, RoleNeighbor, RoleOK}
+
+// Valid проверяет, что роль входит в список допустимых
+func (r UserRole) Valid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 // UserStatus определяет статус верификации
 type UserStatus string
 
@@ -21,6 +34,19 @@ const (
 	StatusRejected UserStatus = "rejected"
 )
 
+// Statuses все допустимые статусы
+var Statuses = []UserStatus{StatusPending, StatusApproved, StatusRejected}
+
+// Valid проверяет, что статус входит в список допустимых
+func (s UserStatus) Valid() bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
 // User представляет пользователя в системе
 type User struct {
 	TelegramID    int64      `json:"telegram_id"`
@@ -34,6 +60,11 @@ type User struct {
 	Status        UserStatus `json:"status"`
 	Role          UserRole   `json:"role"`
 	AdminComment  string     `json:"admin_comment"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	UpdatedBy     string     `json:"updated_by"`
+
+	// Extra значения пользовательских колонок таблицы, о которых бот не знает
+	Extra map[string]string `json:"extra,omitempty"`
 }
 
 // RegistrationState хранит состояние процесса регистрации
@@ -41,6 +72,8 @@ type RegistrationState struct {
 	TelegramID int64
 	Step       int
 	User       User
+	Vouchers   []int64    // соседи, которых заявитель попросил подтвердить заявку
+	Documents  []Document // документы о праве собственности или аренде
 }
 
 // Registration steps
@@ -50,5 +83,7 @@ const (
 	StepPhone
 	StepEmail
 	StepAddress
+	StepVouch
+	StepDocuments
 	StepComplete
 )
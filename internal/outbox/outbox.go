@@ -0,0 +1,316 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"telegram_verification_bot/internal/models"
+)
+
+// Kind определяет тип отложенной записи
+type Kind string
+
+const (
+	KindAddUser      Kind = "add_user"
+	KindUpdateStatus Kind = "update_status"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	baseBackoff  = 5 * time.Second
+	maxBackoff   = 10 * time.Minute
+
+	// StuckAttempts после стольких неудачных попыток запись считается зависшей
+	StuckAttempts = 3
+)
+
+// Item описывает одну запись, ожидающую отправки в таблицу
+type Item struct {
+	ID          int64             `json:"id"`
+	Kind        Kind              `json:"kind"`
+	TelegramID  int64             `json:"telegram_id"`
+	User        *models.User      `json:"user,omitempty"`
+	Status      models.UserStatus `json:"status,omitempty"`
+	Role        models.UserRole   `json:"role,omitempty"`
+	Comment     string            `json:"comment,omitempty"`
+	UpdatedBy   string            `json:"updated_by,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	Attempts    int               `json:"attempts"`
+	LastError   string            `json:"last_error,omitempty"`
+	NextAttempt time.Time         `json:"next_attempt"`
+}
+
+// Stuck сообщает, что запись не удается отправить уже несколько раз
+func (i *Item) Stuck() bool {
+	return i.Attempts >= StuckAttempts
+}
+
+// ApplyFunc записывает элемент в таблицу
+type ApplyFunc func(item *Item) error
+
+// Outbox локальный журнал записей, которые еще не попали в Google Sheets.
+// Каждая запись сначала сохраняется на диск и только потом подтверждается
+// пользователю, а фоновый обработчик досылает ее с повторами.
+type Outbox struct {
+	mutex   sync.Mutex
+	sending sync.Mutex // не дает Run и Flush отправлять одновременно
+	path    string
+	items   []*Item
+	nextID  int64
+	wake    chan struct{}
+}
+
+// Open загружает журнал из файла, создавая каталог при необходимости
+func Open(path string) (*Outbox, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create outbox directory: %v", err)
+	}
+
+	o := &Outbox{
+		path:   path,
+		nextID: 1,
+		wake:   make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read outbox: %v", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &o.items); err != nil {
+			return nil, fmt.Errorf("unable to parse outbox: %v", err)
+		}
+	}
+	for _, item := range o.items {
+		if item.ID >= o.nextID {
+			o.nextID = item.ID + 1
+		}
+	}
+
+	return o, nil
+}
+
+// Enqueue сохраняет запись в журнал. После успешного возврата запись
+// не потеряется даже при перезапуске бота.
+func (o *Outbox) Enqueue(item *Item) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	item.ID = o.nextID
+	item.CreatedAt = time.Now()
+	o.items = append(o.items, item)
+
+	if err := o.save(); err != nil {
+		o.items = o.items[:len(o.items)-1]
+		return err
+	}
+	o.nextID++
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Items возвращает копию всех ожидающих записей
+func (o *Outbox) Items() []Item {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	items := make([]Item, 0, len(o.items))
+	for _, item := range o.items {
+		items = append(items, *item)
+	}
+	return items
+}
+
+// Len возвращает число ожидающих записей
+func (o *Outbox) Len() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	return len(o.items)
+}
+
+// Drop удаляет запись из журнала без отправки
+func (o *Outbox) Drop(id int64) (bool, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for i, item := range o.items {
+		if item.ID == id {
+			o.items = append(o.items[:i], o.items[i+1:]...)
+			return true, o.save()
+		}
+	}
+	return false, nil
+}
+
+// PendingUser накладывает неотправленные записи на пользователя из таблицы.
+// base может быть nil, если в таблице пользователя еще нет. Возвращает nil,
+// если пользователя нет ни в таблице, ни в журнале.
+func (o *Outbox) PendingUser(telegramID int64, base *models.User) *models.User {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	var user *models.User
+	if base != nil {
+		copied := *base
+		user = &copied
+	}
+
+	for _, item := range o.items {
+		if item.TelegramID != telegramID {
+			continue
+		}
+		switch item.Kind {
+		case KindAddUser:
+			if user == nil && item.User != nil {
+				copied := *item.User
+				user = &copied
+			}
+		case KindUpdateStatus:
+			if user != nil {
+				user.Status = item.Status
+				user.Role = item.Role
+				if item.Comment != "" {
+					user.AdminComment = item.Comment
+				}
+			}
+		}
+	}
+
+	return user
+}
+
+// Run досылает записи, пока не отменен контекст
+func (o *Outbox) Run(ctx context.Context, apply ApplyFunc) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		o.process(apply, false)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+	}
+}
+
+// Flush пытается отправить все записи немедленно, не дожидаясь backoff.
+// Возвращает число записей, которые остались в журнале.
+func (o *Outbox) Flush(apply ApplyFunc) int {
+	o.process(apply, true)
+	return o.Len()
+}
+
+// process отправляет готовые записи по порядку. Записи одного пользователя
+// отправляются строго последовательно: если предыдущая не прошла,
+// следующие ждут.
+func (o *Outbox) process(apply ApplyFunc, force bool) {
+	o.sending.Lock()
+	defer o.sending.Unlock()
+
+	blocked := make(map[int64]bool)
+
+	for _, item := range o.ready(force) {
+		if blocked[item.TelegramID] {
+			continue
+		}
+
+		err := apply(item)
+
+		o.mutex.Lock()
+		if err != nil {
+			blocked[item.TelegramID] = true
+			item.Attempts++
+			item.LastError = err.Error()
+			item.NextAttempt = time.Now().Add(backoff(item.Attempts))
+			log.Printf("Outbox item %d (%s) failed, attempt %d: %v", item.ID, item.Kind, item.Attempts, err)
+		} else {
+			o.remove(item.ID)
+		}
+		if err := o.save(); err != nil {
+			log.Printf("Error saving outbox: %v", err)
+		}
+		o.mutex.Unlock()
+	}
+}
+
+// ready возвращает записи, у которых наступило время очередной попытки
+func (o *Outbox) ready(force bool) []*Item {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	now := time.Now()
+	blocked := make(map[int64]bool)
+	var items []*Item
+	for _, item := range o.items {
+		if blocked[item.TelegramID] {
+			continue
+		}
+		if !force && now.Before(item.NextAttempt) {
+			blocked[item.TelegramID] = true
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (o *Outbox) remove(id int64) {
+	for i, item := range o.items {
+		if item.ID == id {
+			o.items = append(o.items[:i], o.items[i+1:]...)
+			return
+		}
+	}
+}
+
+// save атомарно перезаписывает файл журнала
+func (o *Outbox) save() error {
+	data, err := json.MarshalIndent(o.items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to encode outbox: %v", err)
+	}
+
+	tmp := o.path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("unable to write outbox: %v", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("unable to write outbox: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("unable to write outbox: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("unable to write outbox: %v", err)
+	}
+
+	if err := os.Rename(tmp, o.path); err != nil {
+		return fmt.Errorf("unable to write outbox: %v", err)
+	}
+	return nil
+}
+
+func backoff(attempts int) time.Duration {
+	delay := baseBackoff << uint(attempts-1)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	return delay
+}
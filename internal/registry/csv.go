@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"telegram_verification_bot/internal/models"
+)
+
+// Названия колонок, по которым распознается выгрузка реестра
+var (
+	plotHeaders  = []string{"участок", "адрес", "plot", "address"}
+	ownerHeaders = []string{"собственник", "владелец", "фио", "owner", "name"}
+	phoneHeaders = []string{"телефон", "phone"}
+)
+
+// ParseCSV читает реестр собственников из CSV с заголовком. Разделитель
+// (запятая или точка с запятой) определяется по строке заголовков.
+// Возвращает записи и число пропущенных строк без участка.
+func ParseCSV(r io.Reader) ([]models.RegistryEntry, int, error) {
+	buffered := bufio.NewReader(r)
+	firstLine, err := buffered.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, 0, err
+	}
+	header := string(firstLine)
+	if i := strings.IndexByte(header, '\n'); i >= 0 {
+		header = header[:i]
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid CSV: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, 0, fmt.Errorf("empty file")
+	}
+
+	plot, owner, phone := -1, -1, -1
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		switch {
+		case plot < 0 && hasPrefix(name, plotHeaders):
+			plot = i
+		case owner < 0 && hasPrefix(name, ownerHeaders):
+			owner = i
+		case phone < 0 && hasPrefix(name, phoneHeaders):
+			phone = i
+		}
+	}
+	if plot < 0 || (owner < 0 && phone < 0) {
+		return nil, 0, fmt.Errorf("нужны колонки «Участок» и «Собственник» или «Телефон», найдены: %s",
+			strings.Join(records[0], ", "))
+	}
+
+	var entries []models.RegistryEntry
+	skipped := 0
+	for _, record := range records[1:] {
+		entry := models.RegistryEntry{
+			Plot:  models.NormalizeAddress(field(record, plot)),
+			Owner: strings.Join(strings.Fields(field(record, owner)), " "),
+			Phone: field(record, phone),
+		}
+		if entry.Plot == "" {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, skipped, nil
+}
+
+func hasPrefix(name string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func field(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[i])
+}
@@ -0,0 +1,58 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"telegram_verification_bot/internal/models"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    []models.RegistryEntry
+		skipped int
+		wantErr bool
+	}{
+		{
+			name: "comma",
+			data: "Участок,Собственник,Телефон\ngfc p11,Иванов  Иван,+79001234567\n",
+			want: []models.RegistryEntry{{Plot: "GFC P11", Owner: "Иванов Иван", Phone: "+79001234567"}},
+		},
+		{
+			name: "semicolon and english headers in any order",
+			data: "phone;Owner name;Plot\n+7 900;Smith, John;GFP-3\n",
+			want: []models.RegistryEntry{{Plot: "GFP 3", Owner: "Smith, John", Phone: "+7 900"}},
+		},
+		{
+			name:    "bom and owner only",
+			data:    "\ufeffАдрес,ФИО\nGFC 1,Петров\n,Без участка\n",
+			want:    []models.RegistryEntry{{Plot: "GFC 1", Owner: "Петров"}},
+			skipped: 1,
+		},
+		{name: "no plot column", data: "Собственник,Телефон\nИванов,+7\n", wantErr: true},
+		{name: "plot only", data: "Участок\nGFC 1\n", wantErr: true},
+		{name: "empty", data: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, skipped, err := ParseCSV(strings.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCSV: err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if skipped != tt.skipped {
+				t.Errorf("skipped = %d, want %d", skipped, tt.skipped)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d: %+v", len(entries), len(tt.want), entries)
+			}
+			for i := range tt.want {
+				if entries[i] != tt.want[i] {
+					t.Errorf("entry %d = %+v, want %+v", i, entries[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+
+	"telegram_verification_bot/internal/config"
+	"telegram_verification_bot/internal/models"
+)
+
+// Action действие правила над заявкой
+type Action string
+
+const (
+	ActionApprove Action = "approve"
+	ActionFlag    Action = "flag"
+	ActionReject  Action = "reject"
+)
+
+// Facts сведения о заявке, по которым проверяются условия правил
+type Facts struct {
+	User         *models.User
+	PhoneAllowed bool // телефон есть в списке разрешенных
+	Vouches      int  // подтверждения соседей, засчитанные по правилам vouching
+
+	// Сверка с реестром собственников заявленного участка
+	RegistryPhone bool
+	RegistryName  bool
+}
+
+// Decision решение, принятое правилом
+type Decision struct {
+	Rule    string
+	Action  Action
+	Role    models.UserRole
+	Comment string
+}
+
+type rule struct {
+	name          string
+	address       *regexp.Regexp
+	phoneAllowed  bool
+	minVouches    int
+	registryPhone bool
+	registryName  bool
+	decision      Decision
+}
+
+// Engine проверяет заявку по правилам в порядке их объявления
+type Engine struct {
+	rules        []rule
+	useAllowlist bool
+	useVouches   bool
+	useRegistry  bool
+}
+
+// New проверяет и компилирует правила из конфигурации
+func New(configs []config.RuleConfig) (*Engine, error) {
+	engine := &Engine{}
+
+	for i, cfg := range configs {
+		name := cfg.Name
+		if name == "" {
+			name = fmt.Sprintf("правило %d", i+1)
+		}
+
+		r := rule{
+			name:          name,
+			phoneAllowed:  cfg.PhoneAllowlist,
+			minVouches:    cfg.MinVouches,
+			registryPhone: cfg.RegistryPhone,
+			registryName:  cfg.RegistryName,
+			decision: Decision{
+				Rule:    name,
+				Action:  Action(cfg.Action),
+				Role:    models.UserRole(cfg.Role),
+				Comment: cfg.Comment,
+			},
+		}
+
+		if cfg.AddressPattern != "" {
+			pattern, err := regexp.Compile(cfg.AddressPattern)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid address pattern: %v", name, err)
+			}
+			r.address = pattern
+		}
+		if r.address == nil && !r.phoneAllowed && r.minVouches <= 0 && !r.registryPhone && !r.registryName {
+			return nil, fmt.Errorf("rule %q has no conditions", name)
+		}
+
+		switch r.decision.Action {
+		case ActionApprove:
+			if r.decision.Role == "" {
+				r.decision.Role = models.RoleResident
+			}
+			if !r.decision.Role.Valid() || r.decision.Role == models.RoleGuest {
+				return nil, fmt.Errorf("rule %q: invalid role %q", name, cfg.Role)
+			}
+		case ActionReject:
+			r.decision.Role = models.RoleGuest
+		case ActionFlag:
+		default:
+			return nil, fmt.Errorf("rule %q: unknown action %q", name, cfg.Action)
+		}
+		if r.decision.Comment == "" {
+			r.decision.Comment = "Правило «" + name + "»"
+		}
+
+		engine.useAllowlist = engine.useAllowlist || r.phoneAllowed
+		engine.useVouches = engine.useVouches || r.minVouches > 0
+		engine.useRegistry = engine.useRegistry || r.registryPhone || r.registryName
+		engine.rules = append(engine.rules, r)
+	}
+
+	return engine, nil
+}
+
+// Empty сообщает, что правил нет
+func (e *Engine) Empty() bool {
+	return len(e.rules) == 0
+}
+
+// UsesAllowlist сообщает, что какому-то правилу нужен список телефонов
+func (e *Engine) UsesAllowlist() bool {
+	return e.useAllowlist
+}
+
+// UsesVouches сообщает, что какому-то правилу нужны подтверждения соседей
+func (e *Engine) UsesVouches() bool {
+	return e.useVouches
+}
+
+// UsesRegistry сообщает, что какому-то правилу нужна сверка с реестром
+func (e *Engine) UsesRegistry() bool {
+	return e.useRegistry
+}
+
+// Evaluate возвращает решение первого правила, все условия которого выполнены.
+// Правила из skip (уже срабатывавшие для заявки) не проверяются.
+func (e *Engine) Evaluate(facts Facts, skip map[string]bool) *Decision {
+	plot := models.NormalizeAddress(facts.User.Address)
+
+	for _, r := range e.rules {
+		if skip[r.name] {
+			continue
+		}
+		if r.address != nil && !r.address.MatchString(plot) {
+			continue
+		}
+		if r.phoneAllowed && !facts.PhoneAllowed {
+			continue
+		}
+		if r.minVouches > 0 && facts.Vouches < r.minVouches {
+			continue
+		}
+		if (r.registryPhone && !facts.RegistryPhone) || (r.registryName && !facts.RegistryName) {
+			continue
+		}
+		decision := r.decision
+		return &decision
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package rules
+
+import (
+	"testing"
+
+	"telegram_verification_bot/internal/config"
+	"telegram_verification_bot/internal/models"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  config.RuleConfig
+		wantErr bool
+		role    models.UserRole
+	}{
+		{"approve defaults to resident", config.RuleConfig{AddressPattern: "^GFC", Action: "approve"}, false, models.RoleResident},
+		{"approve with role", config.RuleConfig{PhoneAllowlist: true, Action: "approve", Role: "сосед"}, false, models.RoleNeighbor},
+		{"reject sets guest", config.RuleConfig{MinVouches: 2, Action: "reject", Role: "житель"}, false, models.RoleGuest},
+		{"flag", config.RuleConfig{RegistryName: true, Action: "flag"}, false, ""},
+		{"no conditions", config.RuleConfig{Action: "approve"}, true, ""},
+		{"bad pattern", config.RuleConfig{AddressPattern: "(", Action: "approve"}, true, ""},
+		{"unknown action", config.RuleConfig{PhoneAllowlist: true, Action: "ban"}, true, ""},
+		{"approve as guest", config.RuleConfig{PhoneAllowlist: true, Action: "approve", Role: "гость"}, true, ""},
+		{"unknown role", config.RuleConfig{PhoneAllowlist: true, Action: "approve", Role: "admin"}, true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := New([]config.RuleConfig{tt.config})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New: err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := engine.rules[0].decision.Role; got != tt.role {
+				t.Errorf("role = %q, want %q", got, tt.role)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	engine, err := New([]config.RuleConfig{
+		{Name: "reject-outside", AddressPattern: "^XYZ ", Action: "reject"},
+		{Name: "allowlist", AddressPattern: "^GFC ", PhoneAllowlist: true, Action: "approve"},
+		{Name: "vouches", MinVouches: 2, Action: "approve", Role: "сосед"},
+		{Name: "registry", RegistryPhone: true, RegistryName: true, Action: "approve"},
+		{Name: "flag-gfc", AddressPattern: "^GFC ", Action: "flag"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		facts Facts
+		skip  map[string]bool
+		want  string // имя сработавшего правила, пусто — ни одного
+	}{
+		{"first matching rule wins", Facts{User: &models.User{Address: "gfc p11"}, PhoneAllowed: true, Vouches: 5}, nil, "allowlist"},
+		{"address normalized", Facts{User: &models.User{Address: "xyz-1"}}, nil, "reject-outside"},
+		{"all conditions required", Facts{User: &models.User{Address: "GFC P11"}}, nil, "flag-gfc"},
+		{"enough vouches", Facts{User: &models.User{Address: "GFP 3"}, Vouches: 2}, nil, "vouches"},
+		{"too few vouches", Facts{User: &models.User{Address: "GFP 3"}, Vouches: 1}, nil, ""},
+		{"registry needs both", Facts{User: &models.User{Address: "GFP 3"}, RegistryPhone: true}, nil, ""},
+		{"registry match", Facts{User: &models.User{Address: "GFP 3"}, RegistryPhone: true, RegistryName: true}, nil, "registry"},
+		{"skipped rule", Facts{User: &models.User{Address: "GFC P11"}, PhoneAllowed: true}, map[string]bool{"allowlist": true}, "flag-gfc"},
+		{"nothing matches", Facts{User: &models.User{Address: "ABC 1"}}, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := engine.Evaluate(tt.facts, tt.skip)
+			got := ""
+			if decision != nil {
+				got = decision.Rule
+			}
+			if got != tt.want {
+				t.Errorf("Evaluate = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUses(t *testing.T) {
+	engine, err := New([]config.RuleConfig{
+		{AddressPattern: "^GFC", Action: "flag"},
+		{MinVouches: 1, Action: "approve"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if engine.Empty() || engine.UsesAllowlist() || !engine.UsesVouches() || engine.UsesRegistry() {
+		t.Errorf("unexpected flags: empty=%v allowlist=%v vouches=%v registry=%v",
+			engine.Empty(), engine.UsesAllowlist(), engine.UsesVouches(), engine.UsesRegistry())
+	}
+	if decision := engine.Evaluate(Facts{User: &models.User{Address: "GFC 1"}}, nil); decision == nil || decision.Comment != "Правило «правило 1»" {
+		t.Errorf("default name and comment: got %+v", decision)
+	}
+}
@@ -0,0 +1,147 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"telegram_verification_bot/internal/models"
+)
+
+// Служебные вкладки правил автоматической обработки
+const (
+	AuditTab     = "Audit"
+	AllowlistTab = "Allowlist"
+)
+
+// Заголовки вкладки журнала автоматических решений
+const (
+	HeaderAuditID    = "ID"
+	HeaderAuditDate  = "Дата"
+	HeaderAuditUser  = "User ID"
+	HeaderRule       = "Правило"
+	HeaderAction     = "Действие"
+	HeaderAuditRole  = "Роль"
+	HeaderAuditNote  = "Комментарий"
+	HeaderRevertedBy = "Отменено"
+)
+
+// Заголовки вкладки разрешенных телефонов
+const (
+	HeaderAllowedPhone = "Телефон"
+	HeaderAllowedNote  = "Примечание"
+)
+
+var auditHeaders = []string{
+	HeaderAuditID, HeaderAuditDate, HeaderAuditUser, HeaderRule,
+	HeaderAction, HeaderAuditRole, HeaderAuditNote, HeaderRevertedBy,
+}
+
+var allowlistHeaders = []string{HeaderAllowedPhone, HeaderAllowedNote}
+
+// AddAuditEntry записывает автоматическое решение в журнал
+func (s *SheetsService) AddAuditEntry(ctx context.Context, entry *models.AuditEntry) error {
+	err := s.appendTabRow(ctx, AuditTab, auditHeaders, map[string]interface{}{
+		HeaderAuditID:    entry.ID,
+		HeaderAuditDate:  formatTime(entry.CreatedAt),
+		HeaderAuditUser:  entry.TelegramID,
+		HeaderRule:       entry.Rule,
+		HeaderAction:     entry.Action,
+		HeaderAuditRole:  string(entry.Role),
+		HeaderAuditNote:  entry.Comment,
+		HeaderRevertedBy: entry.RevertedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add audit entry: %v", err)
+	}
+	return nil
+}
+
+// GetAuditEntries возвращает автоматические решения по пользователю
+func (s *SheetsService) GetAuditEntries(ctx context.Context, telegramID int64) ([]*models.AuditEntry, error) {
+	columns, rows, err := s.readTab(ctx, AuditTab)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatInt(telegramID, 10)
+	var entries []*models.AuditEntry
+	for _, row := range rows {
+		if columns.cell(row, HeaderAuditUser) == id {
+			entries = append(entries, parseAuditEntry(columns, row))
+		}
+	}
+	return entries, nil
+}
+
+// GetAuditEntry возвращает запись журнала по ID
+func (s *SheetsService) GetAuditEntry(ctx context.Context, id string) (*models.AuditEntry, error) {
+	columns, rows, err := s.readTab(ctx, AuditTab)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if columns.cell(row, HeaderAuditID) == id {
+			return parseAuditEntry(columns, row), nil
+		}
+	}
+	return nil, fmt.Errorf("audit entry %s not found", id)
+}
+
+// MarkAuditReverted отмечает решение отмененным. Возвращает false,
+// если решение не найдено или уже отменено.
+func (s *SheetsService) MarkAuditReverted(ctx context.Context, id, revertedBy string) (bool, error) {
+	reverted := false
+	err := s.editTab(ctx, AuditTab, auditHeaders, func(columns *columnMap, rows [][]interface{}) error {
+		for i, row := range rows {
+			if columns.cell(row, HeaderAuditID) != id {
+				continue
+			}
+			if columns.cell(row, HeaderRevertedBy) != "" {
+				return nil
+			}
+
+			reverted = true
+			return s.updateRow(ctx, AuditTab, columns, i+2, map[string]interface{}{
+				HeaderRevertedBy: fmt.Sprintf("%s, %s", revertedBy, formatTime(time.Now())),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to revert audit entry: %v", err)
+	}
+	return reverted, nil
+}
+
+// GetPhoneAllowlist возвращает нормализованные телефоны из вкладки Allowlist
+func (s *SheetsService) GetPhoneAllowlist(ctx context.Context) (map[string]bool, error) {
+	columns, rows, err := s.readTab(ctx, AllowlistTab)
+	if err != nil {
+		return nil, err
+	}
+
+	phones := make(map[string]bool)
+	for _, row := range rows {
+		if phone := models.NormalizePhone(columns.cell(row, HeaderAllowedPhone)); phone != "" {
+			phones[phone] = true
+		}
+	}
+	return phones, nil
+}
+
+func parseAuditEntry(columns *columnMap, row []interface{}) *models.AuditEntry {
+	telegramID, _ := strconv.ParseInt(columns.cell(row, HeaderAuditUser), 10, 64)
+	return &models.AuditEntry{
+		ID:         columns.cell(row, HeaderAuditID),
+		CreatedAt:  parseTime(columns.cell(row, HeaderAuditDate)),
+		TelegramID: telegramID,
+		Rule:       columns.cell(row, HeaderRule),
+		Action:     columns.cell(row, HeaderAction),
+		Role:       models.UserRole(columns.cell(row, HeaderAuditRole)),
+		Comment:    columns.cell(row, HeaderAuditNote),
+		RevertedBy: columns.cell(row, HeaderRevertedBy),
+	}
+}
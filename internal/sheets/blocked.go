@@ -0,0 +1,53 @@
+package sheets
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// BlockedTab служебная вкладка с пользователями, которым бот не может писать
+const BlockedTab = "Blocked"
+
+// Заголовки вкладки заблокировавших бота
+const (
+	HeaderBlockedUser   = "User ID"
+	HeaderBlockedReason = "Причина"
+	HeaderBlockedDate   = "Дата"
+)
+
+var blockedHeaders = []string{HeaderBlockedUser, HeaderBlockedReason, HeaderBlockedDate}
+
+// GetBlocked возвращает пользователей, заблокировавших бота: Telegram ID -> ответ Telegram
+func (s *SheetsService) GetBlocked(ctx context.Context) (map[int64]string, error) {
+	columns, rows, err := s.readTab(ctx, BlockedTab)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := make(map[int64]string)
+	for _, row := range rows {
+		userID, err := strconv.ParseInt(columns.cell(row, HeaderBlockedUser), 10, 64)
+		if err != nil {
+			continue
+		}
+		blocked[userID] = columns.cell(row, HeaderBlockedReason)
+	}
+	return blocked, nil
+}
+
+// SetBlocked отмечает, что пользователь заблокировал бота
+func (s *SheetsService) SetBlocked(ctx context.Context, telegramID int64, reason string) error {
+	id := strconv.FormatInt(telegramID, 10)
+	return s.upsertTabRow(ctx, BlockedTab, blockedHeaders, byKey(HeaderBlockedUser, id), map[string]interface{}{
+		HeaderBlockedUser:   telegramID,
+		HeaderBlockedReason: reason,
+		HeaderBlockedDate:   formatTime(time.Now()),
+	})
+}
+
+// ClearBlocked снимает отметку, когда пользователь снова пишет боту
+func (s *SheetsService) ClearBlocked(ctx context.Context, telegramID int64) error {
+	id := strconv.FormatInt(telegramID, 10)
+	return s.deleteTabRows(ctx, BlockedTab, byKey(HeaderBlockedUser, id))
+}
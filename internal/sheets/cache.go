@@ -0,0 +1,231 @@
+package sheets
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"telegram_verification_bot/internal/models"
+)
+
+const defaultCacheTTL = 10 * time.Minute
+
+// userCache хранит копию таблицы пользователей в памяти процесса
+type userCache struct {
+	mutex       sync.RWMutex
+	users       []*models.User
+	values      [][]interface{} // строки таблицы для отпечатка
+	byID        map[int64]*models.User
+	rows        map[int64]int // Telegram ID -> номер строки в таблице
+	columns     *columnMap
+	lastRow     int
+	fingerprint string
+	loadedAt    time.Time
+	ttl         time.Duration
+}
+
+func newUserCache(ttl time.Duration) *userCache {
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return &userCache{ttl: ttl}
+}
+
+// fresh сообщает, можно ли отдавать данные из кэша без запроса к API
+func (c *userCache) fresh() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.byID != nil && time.Since(c.loadedAt) < c.ttl
+}
+
+// replace заменяет содержимое кэша строками, прочитанными из таблицы
+func (c *userCache) replace(values [][]interface{}) {
+	users := make([]*models.User, 0, len(values))
+	byID := make(map[int64]*models.User)
+	rows := make(map[int64]int)
+
+	columns := newColumnMap(nil)
+	if len(values) > 0 {
+		columns = newColumnMap(values[0])
+	}
+
+	for i, row := range values {
+		if i == 0 { // Пропускаем заголовки
+			continue
+		}
+
+		user := columns.parseUser(row)
+		if user == nil {
+			continue
+		}
+		users = append(users, user)
+
+		// При дублях учитываем первую строку, как и раньше
+		if _, exists := byID[user.TelegramID]; !exists {
+			byID[user.TelegramID] = user
+			rows[user.TelegramID] = i + 1
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.users = users
+	c.byID = byID
+	c.rows = rows
+	c.columns = columns
+	c.lastRow = len(values)
+	c.values = values
+	c.fingerprint = fingerprintRows(values)
+	c.loadedAt = time.Now()
+}
+
+// invalidate помечает кэш устаревшим
+func (c *userCache) invalidate() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.users = nil
+	c.values = nil
+	c.byID = nil
+	c.rows = nil
+	c.columns = nil
+	c.fingerprint = ""
+}
+
+// columnMap возвращает сопоставление заголовков последней загруженной таблицы
+func (c *userCache) columnMap() *columnMap {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.columns
+}
+
+// get возвращает копию пользователя по Telegram ID
+func (c *userCache) get(telegramID int64) (*models.User, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	user, ok := c.byID[telegramID]
+	if !ok {
+		return nil, false
+	}
+	return copyUser(user), true
+}
+
+// row возвращает номер строки пользователя в таблице
+func (c *userCache) row(telegramID int64) (int, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	row, ok := c.rows[telegramID]
+	return row, ok
+}
+
+// all возвращает копии всех пользователей в порядке строк таблицы
+func (c *userCache) all() []*models.User {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	users := make([]*models.User, 0, len(c.users))
+	for _, user := range c.users {
+		users = append(users, copyUser(user))
+	}
+	return users
+}
+
+// add добавляет пользователя после успешной записи в таблицу. Записанная
+// строка попадает и в отпечаток, чтобы Sync не принял ее за ручную правку.
+func (c *userCache) add(user *models.User, row int, cells []interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.byID == nil {
+		return
+	}
+
+	copied := copyUser(user)
+	c.users = append(c.users, copied)
+	if _, exists := c.byID[user.TelegramID]; !exists {
+		c.byID[user.TelegramID] = copied
+		if row > 0 {
+			c.rows[user.TelegramID] = row
+		}
+	}
+	if row > c.lastRow {
+		c.lastRow = row
+	}
+
+	if row <= 0 {
+		// Куда легла строка, неизвестно: отпечаток сверять не с чем
+		c.fingerprint = ""
+		return
+	}
+	for len(c.values) < row {
+		c.values = append(c.values, nil)
+	}
+	c.values[row-1] = cells
+	c.fingerprint = fingerprintRows(c.values)
+}
+
+// update применяет изменение статуса после успешной записи в таблицу.
+// cells — записанные ячейки по заголовкам, ими обновляется отпечаток.
+func (c *userCache) update(telegramID int64, cells map[string]interface{}, apply func(user *models.User)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if user, ok := c.byID[telegramID]; ok {
+		apply(user)
+	}
+
+	row, ok := c.rows[telegramID]
+	if !ok || c.columns == nil || row > len(c.values) {
+		c.fingerprint = ""
+		return
+	}
+	updated := append([]interface{}(nil), c.values[row-1]...)
+	for header, value := range cells {
+		i, ok := c.columns.column(header)
+		if !ok {
+			continue
+		}
+		for len(updated) <= i {
+			updated = append(updated, "")
+		}
+		updated[i] = value
+	}
+	c.values[row-1] = updated
+	c.fingerprint = fingerprintRows(c.values)
+}
+
+// copyUser копирует пользователя вместе с дополнительными колонками
+func copyUser(user *models.User) *models.User {
+	copied := *user
+	if user.Extra != nil {
+		copied.Extra = make(map[string]string, len(user.Extra))
+		for k, v := range user.Extra {
+			copied.Extra[k] = v
+		}
+	}
+	return &copied
+}
+
+// fingerprintRows считает отпечаток содержимого таблицы для обнаружения правок.
+// API не возвращает пустые ячейки в конце строки, поэтому они не учитываются.
+func fingerprintRows(values [][]interface{}) string {
+	h := sha1.New()
+	for _, row := range values {
+		end := len(row)
+		for end > 0 && fmt.Sprintf("%v", row[end-1]) == "" {
+			end--
+		}
+		for _, cell := range row[:end] {
+			fmt.Fprintf(h, "%v\t", cell)
+		}
+		fmt.Fprint(h, "\n")
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,337 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+	"telegram_verification_bot/internal/models"
+)
+
+// tabSchema описывает служебную вкладку таблицы и ее заголовки
+type tabSchema struct {
+	Name    string
+	Headers []string
+}
+
+// auxTabs служебные вкладки, которые бот ведет помимо таблицы пользователей
+var auxTabs = []tabSchema{
+	{Name: HouseholdsTab, Headers: householdHeaders},
+	{Name: VouchesTab, Headers: vouchHeaders},
+	{Name: DocumentsTab, Headers: documentHeaders},
+	{Name: AuditTab, Headers: auditHeaders},
+	{Name: AllowlistTab, Headers: allowlistHeaders},
+	{Name: RegistryTab, Headers: registryHeaders},
+	{Name: LanguagesTab, Headers: languageHeaders},
+	{Name: TemplatesTab, Headers: templateHeaders},
+	{Name: BlockedTab, Headers: blockedHeaders},
+	{Name: RemindersTab, Headers: reminderHeaders},
+}
+
+// Issue проблема, найденная при проверке таблицы
+type Issue struct {
+	Tab     string
+	Row     int // номер строки в таблице, 0 — проблема всей вкладки
+	Message string
+}
+
+func (i Issue) String() string {
+	if i.Row > 0 {
+		return fmt.Sprintf("%s, строка %d: %s", i.Tab, i.Row, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.Tab, i.Message)
+}
+
+// DoctorReport результат проверки таблицы
+type DoctorReport struct {
+	Issues []Issue
+	Fixed  []string
+}
+
+func (r *DoctorReport) issue(tab string, row int, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Tab: tab, Row: row, Message: fmt.Sprintf(format, args...)})
+}
+
+// Doctor проверяет таблицу на соответствие схеме: вкладки, заголовки,
+// дубли Telegram ID, формат дат и значения статуса и роли. С fix=true
+// добавляет недостающие вкладки и заголовки и ставит выпадающие списки
+// для колонок статуса и роли. Данные пользователей не исправляются.
+func (s *SheetsService) Doctor(ctx context.Context, fix bool) (*DoctorReport, error) {
+	report := &DoctorReport{}
+
+	tabIDs, err := s.tabIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Вкладки
+	expected := auxTabs
+	if s.usersSheet != "" {
+		expected = append([]tabSchema{{Name: s.usersSheet, Headers: UserHeaders}}, auxTabs...)
+	}
+	for _, tab := range expected {
+		if _, ok := tabIDs[tab.Name]; ok {
+			continue
+		}
+		report.issue(tab.Name, 0, "вкладка отсутствует")
+		if fix {
+			id, err := s.addTab(ctx, tab.Name)
+			if err != nil {
+				return report, err
+			}
+			tabIDs[tab.Name] = id
+			report.Fixed = append(report.Fixed, fmt.Sprintf("создана вкладка %q", tab.Name))
+		}
+	}
+
+	// Заголовки служебных вкладок
+	for _, tab := range auxTabs {
+		if _, ok := tabIDs[tab.Name]; !ok {
+			continue
+		}
+		if err := s.checkHeaders(ctx, report, tab.Name, tab.Headers, fix); err != nil {
+			return report, err
+		}
+	}
+
+	// Таблица пользователей
+	usersTab := s.usersSheet
+	if usersTab == "" {
+		usersTab = s.firstTabName()
+	}
+	if _, ok := tabIDs[usersTab]; !ok {
+		return report, nil
+	}
+	if err := s.checkHeaders(ctx, report, usersTab, UserHeaders, fix); err != nil {
+		return report, err
+	}
+	s.cache.invalidate()
+
+	values, err := s.fetchRows(ctx)
+	if err != nil {
+		return report, fmt.Errorf("unable to read users: %v", err)
+	}
+	if len(values) == 0 {
+		return report, nil
+	}
+	columns := newColumnMap(values[0])
+	checkUserRows(report, usersTab, columns, values)
+
+	if fix {
+		if err := s.applyValidation(ctx, tabIDs[usersTab], columns); err != nil {
+			return report, err
+		}
+		report.Fixed = append(report.Fixed, "выпадающие списки для колонок статуса и роли")
+	}
+
+	return report, nil
+}
+
+// checkUserRows ищет дубли, неверные даты и неизвестные значения статуса и роли
+func checkUserRows(report *DoctorReport, tab string, columns *columnMap, values [][]interface{}) {
+	seen := make(map[string]int)
+
+	for i, row := range values {
+		if i == 0 || len(row) == 0 {
+			continue
+		}
+		rowIndex := i + 1
+
+		id := columns.cell(row, HeaderTelegramID)
+		if id == "" {
+			report.issue(tab, rowIndex, "пустой User ID")
+		} else if first, ok := seen[id]; ok {
+			report.issue(tab, rowIndex, "User ID %s повторяет строку %d", id, first)
+		} else {
+			seen[id] = rowIndex
+		}
+
+		for _, header := range []string{HeaderRegisterDate, HeaderUpdatedAt} {
+			value := columns.cell(row, header)
+			if value != "" && parseTime(value).IsZero() {
+				report.issue(tab, rowIndex, "%s %q не в формате %s", header, value, timeLayout)
+			}
+		}
+
+		if status := models.UserStatus(columns.cell(row, HeaderStatus)); !status.Valid() {
+			report.issue(tab, rowIndex, "неизвестный статус %q", status)
+		}
+		if role := models.UserRole(columns.cell(row, HeaderRole)); !role.Valid() {
+			report.issue(tab, rowIndex, "неизвестная роль %q", role)
+		}
+	}
+}
+
+// checkHeaders сверяет первую строку вкладки со схемой и дописывает
+// недостающие заголовки справа, не трогая существующие колонки
+func (s *SheetsService) checkHeaders(ctx context.Context, report *DoctorReport, tab string, headers []string, fix bool) error {
+	var resp *sheets.ValueRange
+	err := s.call(ctx, "read headers", func(ctx context.Context) error {
+		var err error
+		resp, err = s.service.Spreadsheets.Values.Get(s.spreadsheetID, tabA1(tab, "1:1")).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to read headers of %s: %v", tab, err)
+	}
+
+	var header []interface{}
+	if len(resp.Values) > 0 {
+		header = resp.Values[0]
+	}
+	columns := newColumnMap(header)
+	missing := columns.missing(headers)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	report.issue(tab, 1, "нет колонок: %s", strings.Join(missing, ", "))
+	if !fix {
+		return nil
+	}
+
+	// Пустые ячейки в конце строки заголовков не считаются колонками
+	last := len(columns.headers)
+	for last > 0 && columns.headers[last-1] == "" {
+		last--
+	}
+	row := make([]interface{}, len(missing))
+	for i, name := range missing {
+		row[i] = name
+	}
+	a1 := fmt.Sprintf("%s1:%s1", columnLetter(last), columnLetter(last+len(missing)-1))
+
+	err = s.call(ctx, "write headers", func(ctx context.Context) error {
+		_, err := s.service.Spreadsheets.Values.Update(
+			s.spreadsheetID,
+			tabA1(tab, a1),
+			&sheets.ValueRange{Values: [][]interface{}{row}},
+		).ValueInputOption("RAW").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to write headers of %s: %v", tab, err)
+	}
+
+	report.Fixed = append(report.Fixed, fmt.Sprintf("%s: добавлены колонки %s", tab, strings.Join(missing, ", ")))
+	return nil
+}
+
+// firstTabName возвращает первую вкладку, запомненную tabIDs
+func (s *SheetsService) firstTabName() string {
+	s.tabs.mutex.Lock()
+	defer s.tabs.mutex.Unlock()
+	return s.firstTab
+}
+
+// tabIDs возвращает идентификаторы вкладок по их именам и запоминает первую вкладку
+func (s *SheetsService) tabIDs(ctx context.Context) (map[string]int64, error) {
+	var resp *sheets.Spreadsheet
+	err := s.call(ctx, "read spreadsheet", func(ctx context.Context) error {
+		var err error
+		resp, err = s.service.Spreadsheets.Get(s.spreadsheetID).
+			Fields("sheets.properties").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read spreadsheet: %v", err)
+	}
+
+	ids := make(map[string]int64)
+	for i, sheet := range resp.Sheets {
+		if sheet.Properties == nil {
+			continue
+		}
+		ids[sheet.Properties.Title] = sheet.Properties.SheetId
+		if i == 0 {
+			s.tabs.mutex.Lock()
+			s.firstTab = sheet.Properties.Title
+			s.tabs.mutex.Unlock()
+		}
+	}
+	return ids, nil
+}
+
+// addTab создает вкладку и возвращает ее идентификатор
+func (s *SheetsService) addTab(ctx context.Context, name string) (int64, error) {
+	request := &sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{{
+			AddSheet: &sheets.AddSheetRequest{
+				Properties: &sheets.SheetProperties{Title: name},
+			},
+		}},
+	}
+
+	var resp *sheets.BatchUpdateSpreadsheetResponse
+	err := s.call(ctx, "add tab", func(ctx context.Context) error {
+		var err error
+		resp, err = s.service.Spreadsheets.BatchUpdate(s.spreadsheetID, request).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to add tab %s: %v", name, err)
+	}
+
+	if len(resp.Replies) > 0 && resp.Replies[0].AddSheet != nil {
+		return resp.Replies[0].AddSheet.Properties.SheetId, nil
+	}
+	return 0, nil
+}
+
+// applyValidation ставит выпадающие списки допустимых значений для статуса и роли
+func (s *SheetsService) applyValidation(ctx context.Context, sheetID int64, columns *columnMap) error {
+	var statuses, roles []string
+	for _, status := range models.Statuses {
+		statuses = append(statuses, string(status))
+	}
+	for _, role := range models.Roles {
+		roles = append(roles, string(role))
+	}
+
+	var requests []*sheets.Request
+	for header, allowed := range map[string][]string{HeaderStatus: statuses, HeaderRole: roles} {
+		column, ok := columns.column(header)
+		if !ok {
+			continue
+		}
+
+		var conditionValues []*sheets.ConditionValue
+		for _, value := range allowed {
+			conditionValues = append(conditionValues, &sheets.ConditionValue{UserEnteredValue: value})
+		}
+
+		requests = append(requests, &sheets.Request{
+			SetDataValidation: &sheets.SetDataValidationRequest{
+				Range: &sheets.GridRange{
+					SheetId:          sheetID,
+					StartRowIndex:    1, // без строки заголовков
+					StartColumnIndex: int64(column),
+					EndColumnIndex:   int64(column + 1),
+				},
+				Rule: &sheets.DataValidationRule{
+					Condition: &sheets.BooleanCondition{
+						Type:   "ONE_OF_LIST",
+						Values: conditionValues,
+					},
+					ShowCustomUi: true,
+					Strict:       true,
+				},
+			},
+		})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+
+	err := s.call(ctx, "apply validation", func(ctx context.Context) error {
+		_, err := s.service.Spreadsheets.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: requests,
+		}).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to apply data validation: %v", err)
+	}
+	return nil
+}
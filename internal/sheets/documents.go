@@ -0,0 +1,98 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"telegram_verification_bot/internal/models"
+)
+
+// DocumentsTab служебная вкладка с документами заявителей
+const DocumentsTab = "Documents"
+
+// Заголовки вкладки документов
+const (
+	HeaderDocApplicantID = "Заявитель ID"
+	HeaderFileID         = "File ID"
+	HeaderFileKind       = "Тип файла"
+	HeaderFileName       = "Имя файла"
+	HeaderMimeType       = "MIME"
+	HeaderFileSize       = "Размер"
+	HeaderUploadedAt     = "Загружен"
+	HeaderAdminMessage   = "Сообщение модератору"
+)
+
+var documentHeaders = []string{
+	HeaderDocApplicantID, HeaderFileID, HeaderFileKind, HeaderFileName,
+	HeaderMimeType, HeaderFileSize, HeaderUploadedAt, HeaderAdminMessage,
+}
+
+// AddDocument записывает сведения о загруженном документе
+func (s *SheetsService) AddDocument(ctx context.Context, doc *models.Document) error {
+	err := s.appendTabRow(ctx, DocumentsTab, documentHeaders, map[string]interface{}{
+		HeaderDocApplicantID: doc.ApplicantID,
+		HeaderFileID:         doc.FileID,
+		HeaderFileKind:       string(doc.Kind),
+		HeaderFileName:       doc.FileName,
+		HeaderMimeType:       doc.MimeType,
+		HeaderFileSize:       doc.Size,
+		HeaderUploadedAt:     formatTime(doc.UploadedAt),
+		HeaderAdminMessage:   doc.AdminMessageID,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add document: %v", err)
+	}
+	return nil
+}
+
+// GetDocuments возвращает документы всех заявителей
+func (s *SheetsService) GetDocuments(ctx context.Context) ([]*models.Document, error) {
+	columns, rows, err := s.readTab(ctx, DocumentsTab)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []*models.Document
+	for _, row := range rows {
+		applicantID, err := strconv.ParseInt(columns.cell(row, HeaderDocApplicantID), 10, 64)
+		if err != nil {
+			continue
+		}
+		size, _ := strconv.Atoi(columns.cell(row, HeaderFileSize))
+		messageID, _ := strconv.Atoi(columns.cell(row, HeaderAdminMessage))
+
+		docs = append(docs, &models.Document{
+			ApplicantID:    applicantID,
+			FileID:         columns.cell(row, HeaderFileID),
+			Kind:           models.DocumentKind(columns.cell(row, HeaderFileKind)),
+			FileName:       columns.cell(row, HeaderFileName),
+			MimeType:       columns.cell(row, HeaderMimeType),
+			Size:           size,
+			UploadedAt:     parseTime(columns.cell(row, HeaderUploadedAt)),
+			AdminMessageID: messageID,
+		})
+	}
+	return docs, nil
+}
+
+// DeleteDocuments удаляет строки с документами заявителя и возвращает их число
+func (s *SheetsService) DeleteDocuments(ctx context.Context, applicantID int64) (int, error) {
+	id := strconv.FormatInt(applicantID, 10)
+	var rowIndexes []int
+	err := s.editTab(ctx, DocumentsTab, nil, func(columns *columnMap, rows [][]interface{}) error {
+		for i, row := range rows {
+			if columns.cell(row, HeaderDocApplicantID) == id {
+				rowIndexes = append(rowIndexes, i+2)
+			}
+		}
+		if len(rowIndexes) == 0 {
+			return nil
+		}
+		return s.deleteRows(ctx, DocumentsTab, rowIndexes)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to delete documents: %v", err)
+	}
+	return len(rowIndexes), nil
+}
@@ -0,0 +1,138 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"telegram_verification_bot/internal/models"
+)
+
+// Служебные вкладки домохозяйств и подтверждений
+const (
+	HouseholdsTab = "Households"
+	VouchesTab    = "Vouches"
+)
+
+// Заголовки вкладки домохозяйств
+const (
+	HeaderPlot       = "Участок"
+	HeaderOwnerID    = "Владелец ID"
+	HeaderAssignedAt = "Назначен"
+	HeaderAssignedBy = "Кем назначен"
+)
+
+// Заголовки вкладки подтверждений
+const (
+	HeaderApplicantID = "Заявитель ID"
+	HeaderVoucherID   = "Поручитель ID"
+	HeaderVouchKind   = "Тип"
+	HeaderConfirmed   = "Подтверждено" // ожидает / да / нет
+	HeaderVouchedAt   = "Дата"
+)
+
+var householdHeaders = []string{HeaderPlot, HeaderOwnerID, HeaderAssignedAt, HeaderAssignedBy}
+
+var vouchHeaders = []string{HeaderApplicantID, HeaderVoucherID, HeaderVouchKind, HeaderConfirmed, HeaderVouchedAt}
+
+// GetHouseholdOwners возвращает основных владельцев участков: участок -> Telegram ID
+func (s *SheetsService) GetHouseholdOwners(ctx context.Context) (map[string]int64, error) {
+	columns, rows, err := s.readTab(ctx, HouseholdsTab)
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string]int64)
+	for _, row := range rows {
+		plot := models.NormalizeAddress(columns.cell(row, HeaderPlot))
+		ownerID, err := strconv.ParseInt(columns.cell(row, HeaderOwnerID), 10, 64)
+		if plot == "" || err != nil {
+			continue
+		}
+		owners[plot] = ownerID
+	}
+	return owners, nil
+}
+
+// SetHouseholdOwner назначает основного владельца участка, заменяя прежнего
+func (s *SheetsService) SetHouseholdOwner(ctx context.Context, plot string, ownerID int64, assignedBy string) error {
+	plot = models.NormalizeAddress(plot)
+	samePlot := func(columns *columnMap, row []interface{}) bool {
+		return models.NormalizeAddress(columns.cell(row, HeaderPlot)) == plot
+	}
+	return s.upsertTabRow(ctx, HouseholdsTab, householdHeaders, samePlot, map[string]interface{}{
+		HeaderPlot:       plot,
+		HeaderOwnerID:    ownerID,
+		HeaderAssignedAt: formatTime(time.Now()),
+		HeaderAssignedBy: assignedBy,
+	})
+}
+
+// AddVouch записывает подтверждение заявки другим жителем или запрос на него
+func (s *SheetsService) AddVouch(ctx context.Context, vouch *models.Vouch) error {
+	err := s.appendTabRow(ctx, VouchesTab, vouchHeaders, map[string]interface{}{
+		HeaderApplicantID: vouch.ApplicantID,
+		HeaderVoucherID:   vouch.VoucherID,
+		HeaderVouchKind:   string(vouch.Kind),
+		HeaderConfirmed:   string(vouch.Status),
+		HeaderVouchedAt:   formatTime(vouch.CreatedAt),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add vouch: %v", err)
+	}
+	return nil
+}
+
+// UpdateVouchStatus записывает ответ поручителя на ожидающий запрос.
+// Возвращает false, если ожидающего запроса нет.
+func (s *SheetsService) UpdateVouchStatus(ctx context.Context, applicantID, voucherID int64, status models.VouchStatus) (bool, error) {
+	applicant := strconv.FormatInt(applicantID, 10)
+	voucher := strconv.FormatInt(voucherID, 10)
+	updated := false
+	err := s.editTab(ctx, VouchesTab, vouchHeaders, func(columns *columnMap, rows [][]interface{}) error {
+		for i, row := range rows {
+			if columns.cell(row, HeaderApplicantID) != applicant || columns.cell(row, HeaderVoucherID) != voucher ||
+				models.VouchStatus(columns.cell(row, HeaderConfirmed)) != models.VouchPending {
+				continue
+			}
+
+			updated = true
+			return s.updateRow(ctx, VouchesTab, columns, i+2, map[string]interface{}{
+				HeaderConfirmed: string(status),
+				HeaderVouchedAt: formatTime(time.Now()),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to update vouch: %v", err)
+	}
+	return updated, nil
+}
+
+// GetVouches возвращает подтверждения по заявке пользователя
+func (s *SheetsService) GetVouches(ctx context.Context, applicantID int64) ([]*models.Vouch, error) {
+	columns, rows, err := s.readTab(ctx, VouchesTab)
+	if err != nil {
+		return nil, err
+	}
+
+	id := strconv.FormatInt(applicantID, 10)
+	var vouches []*models.Vouch
+	for _, row := range rows {
+		if columns.cell(row, HeaderApplicantID) != id {
+			continue
+		}
+
+		voucherID, _ := strconv.ParseInt(columns.cell(row, HeaderVoucherID), 10, 64)
+		vouches = append(vouches, &models.Vouch{
+			ApplicantID: applicantID,
+			VoucherID:   voucherID,
+			Kind:        models.VouchKind(columns.cell(row, HeaderVouchKind)),
+			Status:      models.VouchStatus(columns.cell(row, HeaderConfirmed)),
+			CreatedAt:   parseTime(columns.cell(row, HeaderVouchedAt)),
+		})
+	}
+	return vouches, nil
+}
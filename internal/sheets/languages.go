@@ -0,0 +1,48 @@
+package sheets
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// LanguagesTab служебная вкладка с языком интерфейса, выбранным пользователями
+const LanguagesTab = "Languages"
+
+// Заголовки вкладки языков
+const (
+	HeaderLanguageUser = "User ID"
+	HeaderLanguage     = "Язык"
+	HeaderLanguageDate = "Дата"
+)
+
+var languageHeaders = []string{HeaderLanguageUser, HeaderLanguage, HeaderLanguageDate}
+
+// GetLanguages возвращает выбранные языки: Telegram ID -> код языка
+func (s *SheetsService) GetLanguages(ctx context.Context) (map[int64]string, error) {
+	columns, rows, err := s.readTab(ctx, LanguagesTab)
+	if err != nil {
+		return nil, err
+	}
+
+	languages := make(map[int64]string)
+	for _, row := range rows {
+		userID, err := strconv.ParseInt(columns.cell(row, HeaderLanguageUser), 10, 64)
+		lang := columns.cell(row, HeaderLanguage)
+		if err != nil || lang == "" {
+			continue
+		}
+		languages[userID] = lang
+	}
+	return languages, nil
+}
+
+// SetLanguage сохраняет язык пользователя, заменяя прежний
+func (s *SheetsService) SetLanguage(ctx context.Context, telegramID int64, lang string) error {
+	id := strconv.FormatInt(telegramID, 10)
+	return s.upsertTabRow(ctx, LanguagesTab, languageHeaders, byKey(HeaderLanguageUser, id), map[string]interface{}{
+		HeaderLanguageUser: telegramID,
+		HeaderLanguage:     lang,
+		HeaderLanguageDate: formatTime(time.Now()),
+	})
+}
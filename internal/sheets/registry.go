@@ -0,0 +1,91 @@
+package sheets
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/sheets/v4"
+	"telegram_verification_bot/internal/models"
+)
+
+// RegistryTab служебная вкладка с реестром собственников участков
+const RegistryTab = "Registry"
+
+// Заголовки вкладки реестра
+const (
+	HeaderRegistryPlot  = "Участок"
+	HeaderRegistryOwner = "Собственник"
+	HeaderRegistryPhone = "Телефон"
+)
+
+var registryHeaders = []string{HeaderRegistryPlot, HeaderRegistryOwner, HeaderRegistryPhone}
+
+// GetRegistry возвращает реестр собственников
+func (s *SheetsService) GetRegistry(ctx context.Context) ([]models.RegistryEntry, error) {
+	columns, rows, err := s.readTab(ctx, RegistryTab)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.RegistryEntry
+	for _, row := range rows {
+		plot := models.NormalizeAddress(columns.cell(row, HeaderRegistryPlot))
+		if plot == "" {
+			continue
+		}
+		entries = append(entries, models.RegistryEntry{
+			Plot:  plot,
+			Owner: columns.cell(row, HeaderRegistryOwner),
+			Phone: columns.cell(row, HeaderRegistryPhone),
+		})
+	}
+	return entries, nil
+}
+
+// ReplaceRegistry заменяет содержимое реестра. Новые строки записываются
+// поверх старых, а затем очищается остаток, поэтому реестр не бывает пустым
+// во время импорта.
+func (s *SheetsService) ReplaceRegistry(ctx context.Context, entries []models.RegistryEntry) error {
+	tabIDs, err := s.tabIDs(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := tabIDs[RegistryTab]; !ok {
+		if _, err := s.addTab(ctx, RegistryTab); err != nil {
+			return err
+		}
+	}
+
+	values := [][]interface{}{{HeaderRegistryPlot, HeaderRegistryOwner, HeaderRegistryPhone}}
+	for _, entry := range entries {
+		values = append(values, []interface{}{entry.Plot, entry.Owner, entry.Phone})
+	}
+
+	defer s.invalidateTab(RegistryTab)
+
+	err = s.call(ctx, "write registry", func(ctx context.Context) error {
+		_, err := s.service.Spreadsheets.Values.Update(
+			s.spreadsheetID,
+			tabA1(RegistryTab, "A1"),
+			&sheets.ValueRange{Values: values},
+		).ValueInputOption("RAW").Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to write registry: %v", err)
+	}
+
+	rest := fmt.Sprintf("A%d:C", len(values)+1)
+	err = s.call(ctx, "clear registry", func(ctx context.Context) error {
+		_, err := s.service.Spreadsheets.Values.Clear(
+			s.spreadsheetID,
+			tabA1(RegistryTab, rest),
+			&sheets.ClearValuesRequest{},
+		).Context(ctx).Do()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to clear old registry rows: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package sheets
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// RemindersTab служебная вкладка со временем последнего напоминания заявителю
+const RemindersTab = "Reminders"
+
+// Заголовки вкладки напоминаний
+const (
+	HeaderRemindedUser = "User ID"
+	HeaderRemindedAt   = "Дата"
+)
+
+var reminderHeaders = []string{HeaderRemindedUser, HeaderRemindedAt}
+
+// GetReminders возвращает время последнего напоминания: Telegram ID -> время
+func (s *SheetsService) GetReminders(ctx context.Context) (map[int64]time.Time, error) {
+	columns, rows, err := s.readTab(ctx, RemindersTab)
+	if err != nil {
+		return nil, err
+	}
+
+	reminded := make(map[int64]time.Time)
+	for _, row := range rows {
+		userID, err := strconv.ParseInt(columns.cell(row, HeaderRemindedUser), 10, 64)
+		at := parseTime(columns.cell(row, HeaderRemindedAt))
+		if err != nil || at.IsZero() {
+			continue
+		}
+		reminded[userID] = at
+	}
+	return reminded, nil
+}
+
+// SetReminded запоминает время напоминания заявителю, заменяя прежнее
+func (s *SheetsService) SetReminded(ctx context.Context, telegramID int64, at time.Time) error {
+	id := strconv.FormatInt(telegramID, 10)
+	return s.upsertTabRow(ctx, RemindersTab, reminderHeaders, byKey(HeaderRemindedUser, id), map[string]interface{}{
+		HeaderRemindedUser: telegramID,
+		HeaderRemindedAt:   formatTime(at),
+	})
+}
@@ -0,0 +1,196 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+const (
+	defaultMaxAttempts       = 5
+	defaultRequestsPerMinute = 60 // квота Sheets API на чтение/запись на пользователя
+	defaultRequestTimeout    = 30 * time.Second
+	baseBackoff              = 500 * time.Millisecond
+	maxBackoff               = 30 * time.Second
+)
+
+// rateLimiter ограничивает частоту запросов к API по принципу token bucket
+type rateLimiter struct {
+	mutex    sync.Mutex
+	capacity float64
+	tokens   float64
+	perSec   float64
+	updated  time.Time
+}
+
+func newRateLimiter(perMinute int) *rateLimiter {
+	if perMinute <= 0 {
+		perMinute = defaultRequestsPerMinute
+	}
+	return &rateLimiter{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		perSec:   float64(perMinute) / 60,
+		updated:  time.Now(),
+	}
+}
+
+// wait блокируется, пока не освободится квота или не отменится контекст
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mutex.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.updated).Seconds() * l.perSec
+		if l.tokens > l.capacity {
+			l.tokens = l.capacity
+		}
+		l.updated = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mutex.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - l.tokens) / l.perSec * float64(time.Second))
+		l.mutex.Unlock()
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// SetRetryPolicy задает число попыток и ограничение запросов в минуту
+func (s *SheetsService) SetRetryPolicy(maxAttempts, requestsPerMinute int) {
+	if maxAttempts > 0 {
+		s.maxAttempts = maxAttempts
+	}
+	s.limiter = newRateLimiter(requestsPerMinute)
+}
+
+// call выполняет запрос к API с учетом квоты и повторяет его при временных ошибках.
+// Отмена ctx прерывает и ожидание квоты, и паузы между попытками.
+func (s *SheetsService) call(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	return s.retry(ctx, op, fn, isRetryable)
+}
+
+// callOnce выполняет неидемпотентный запрос: дописывание или удаление строк.
+// После таймаута или ответа 5xx запрос мог уже выполниться, и повтор задвоил
+// бы строку или удалил соседнюю, поэтому повторяется только запрос, который
+// API заведомо отклонил из-за квоты.
+func (s *SheetsService) callOnce(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	return s.retry(ctx, op, fn, isRejected)
+}
+
+// retry выполняет запрос, повторяя его, пока retryable считает ошибку временной
+func (s *SheetsService) retry(ctx context.Context, op string, fn func(ctx context.Context) error, retryable func(error) bool) error {
+	var err error
+	for attempt := 0; attempt < s.maxAttempts; attempt++ {
+		if err := s.limiter.wait(ctx); err != nil {
+			return err
+		}
+
+		requestCtx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
+		err = fn(requestCtx)
+		cancel()
+
+		if err == nil || !retryable(err) || attempt == s.maxAttempts-1 {
+			return err
+		}
+
+		delay := backoff(attempt, err)
+		log.Printf("Sheets %s failed (attempt %d/%d), retrying in %v: %v",
+			op, attempt+1, s.maxAttempts, delay, err)
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// isRetryable определяет, имеет ли смысл повторить запрос
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusTooManyRequests, http.StatusInternalServerError,
+			http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		case http.StatusForbidden:
+			for _, item := range apiErr.Errors {
+				if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isRejected определяет ошибки квоты: API отклонил запрос, не выполняя его
+func isRejected(err error) bool {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Code {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		for _, item := range apiErr.Errors {
+			if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// backoff считает экспоненциальную задержку с джиттером, учитывая Retry-After
+func backoff(attempt int, err error) time.Duration {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Header != nil {
+		if seconds, convErr := strconv.Atoi(apiErr.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	delay := baseBackoff << uint(attempt)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	// Половина задержки фиксирована, половина случайна
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// sleepContext ждет указанное время или отмены контекста
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
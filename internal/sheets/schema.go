@@ -0,0 +1,205 @@
+package sheets
+
+import (
+	"fmt"
+	"strings"
+
+	"telegram_verification_bot/internal/models"
+)
+
+// Заголовки колонок таблицы пользователей. Колонки ищутся по заголовку,
+// поэтому их можно переставлять и добавлять между ними свои.
+const (
+	HeaderTelegramID   = "User ID"
+	HeaderUsername     = "Username"
+	HeaderFirstName    = "Имя"
+	HeaderLastName     = "Фамилия"
+	HeaderPhone        = "Телефон"
+	HeaderEmail        = "Email"
+	HeaderAddress      = "Адрес"
+	HeaderRegisterDate = "Дата регистрации"
+	HeaderStatus       = "Статус"
+	HeaderRole         = "Роль"
+	HeaderAdminComment = "Админ комментарий"
+	HeaderUpdatedAt    = "Дата обновления"
+	HeaderUpdatedBy    = "Кем обновлено"
+)
+
+// UserHeaders порядок колонок для новой таблицы
+var UserHeaders = []string{
+	HeaderTelegramID, HeaderUsername, HeaderFirstName, HeaderLastName, HeaderPhone,
+	HeaderEmail, HeaderAddress, HeaderRegisterDate, HeaderStatus, HeaderRole, HeaderAdminComment,
+	HeaderUpdatedAt, HeaderUpdatedBy,
+}
+
+// RequiredHeaders без этих колонок бот отказывается писать в таблицу.
+// Колонки отметки об изменении необязательны: таблицы, созданные до их
+// появления, продолжают работать.
+var RequiredHeaders = UserHeaders[:11]
+
+// usersRange диапазон чтения таблицы пользователей с запасом под свои колонки
+const usersRange = "A:ZZ"
+
+// MissingHeadersError возвращается при попытке записи в таблицу без нужных колонок
+type MissingHeadersError struct {
+	Headers []string
+}
+
+func (e *MissingHeadersError) Error() string {
+	return fmt.Sprintf("missing required headers: %s", strings.Join(e.Headers, ", "))
+}
+
+// columnMap сопоставляет заголовки колонок с их позициями в таблице
+type columnMap struct {
+	headers []string
+	index   map[string]int
+}
+
+func newColumnMap(header []interface{}) *columnMap {
+	m := &columnMap{index: make(map[string]int)}
+	for i, cell := range header {
+		name := strings.TrimSpace(fmt.Sprintf("%v", cell))
+		m.headers = append(m.headers, name)
+		key := strings.ToLower(name)
+		if _, exists := m.index[key]; !exists && name != "" {
+			m.index[key] = i
+		}
+	}
+	return m
+}
+
+// column возвращает номер колонки (с нуля) по заголовку
+func (m *columnMap) column(header string) (int, bool) {
+	i, ok := m.index[strings.ToLower(header)]
+	return i, ok
+}
+
+// letter возвращает буквенное обозначение колонки по заголовку
+func (m *columnMap) letter(header string) (string, bool) {
+	i, ok := m.column(header)
+	if !ok {
+		return "", false
+	}
+	return columnLetter(i), true
+}
+
+// missing возвращает заголовки из списка, которых нет в таблице
+func (m *columnMap) missing(headers []string) []string {
+	var result []string
+	for _, header := range headers {
+		if _, ok := m.column(header); !ok {
+			result = append(result, header)
+		}
+	}
+	return result
+}
+
+// requireWritable проверяет, что в таблице есть все обязательные колонки
+func (m *columnMap) requireWritable() error {
+	if missing := m.missing(RequiredHeaders); len(missing) > 0 {
+		return &MissingHeadersError{Headers: missing}
+	}
+	return nil
+}
+
+// cell возвращает значение ячейки строки по заголовку
+func (m *columnMap) cell(row []interface{}, header string) string {
+	i, ok := m.column(header)
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return fmt.Sprintf("%v", row[i])
+}
+
+// parseUser разбирает строку таблицы в пользователя. Значения колонок,
+// о которых бот не знает, сохраняются в User.Extra.
+func (m *columnMap) parseUser(row []interface{}) *models.User {
+	if len(row) == 0 {
+		return nil
+	}
+
+	user := &models.User{
+		Username:     m.cell(row, HeaderUsername),
+		FirstName:    m.cell(row, HeaderFirstName),
+		LastName:     m.cell(row, HeaderLastName),
+		Phone:        m.cell(row, HeaderPhone),
+		Email:        m.cell(row, HeaderEmail),
+		Address:      m.cell(row, HeaderAddress),
+		RegisterDate: parseTime(m.cell(row, HeaderRegisterDate)),
+		Status:       models.UserStatus(m.cell(row, HeaderStatus)),
+		Role:         models.UserRole(m.cell(row, HeaderRole)),
+		AdminComment: m.cell(row, HeaderAdminComment),
+		UpdatedAt:    parseTime(m.cell(row, HeaderUpdatedAt)),
+		UpdatedBy:    m.cell(row, HeaderUpdatedBy),
+	}
+	id := m.cell(row, HeaderTelegramID)
+	if id == "" {
+		return nil
+	}
+	fmt.Sscanf(id, "%d", &user.TelegramID)
+
+	for i, header := range m.headers {
+		if header == "" || isKnownHeader(header) || i >= len(row) {
+			continue
+		}
+		if user.Extra == nil {
+			user.Extra = make(map[string]string)
+		}
+		user.Extra[header] = fmt.Sprintf("%v", row[i])
+	}
+
+	return user
+}
+
+// userRow строит строку для записи в таблицу в порядке ее колонок
+func (m *columnMap) userRow(user *models.User) []interface{} {
+	row := make([]interface{}, len(m.headers))
+	for i := range row {
+		row[i] = ""
+	}
+
+	set := func(header string, value interface{}) {
+		if i, ok := m.column(header); ok {
+			row[i] = value
+		}
+	}
+	set(HeaderTelegramID, user.TelegramID)
+	set(HeaderUsername, user.Username)
+	set(HeaderFirstName, user.FirstName)
+	set(HeaderLastName, user.LastName)
+	set(HeaderPhone, user.Phone)
+	set(HeaderEmail, user.Email)
+	set(HeaderAddress, user.Address)
+	set(HeaderRegisterDate, formatTime(user.RegisterDate))
+	set(HeaderStatus, string(user.Status))
+	set(HeaderRole, string(user.Role))
+	set(HeaderAdminComment, user.AdminComment)
+	set(HeaderUpdatedAt, formatTime(user.UpdatedAt))
+	set(HeaderUpdatedBy, user.UpdatedBy)
+	for header, value := range user.Extra {
+		if !isKnownHeader(header) {
+			set(header, value)
+		}
+	}
+
+	return row
+}
+
+func isKnownHeader(header string) bool {
+	for _, known := range UserHeaders {
+		if strings.EqualFold(known, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// columnLetter переводит номер колонки (с нуля) в буквы: 0 -> A, 26 -> AA
+func columnLetter(i int) string {
+	letters := ""
+	for i >= 0 {
+		letters = string(rune('A'+i%26)) + letters
+		i = i/26 - 1
+	}
+	return letters
+}
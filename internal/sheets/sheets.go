@@ -2,7 +2,13 @@ package sheets
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"google.golang.org/api/option"
@@ -10,14 +16,29 @@ import (
 	"telegram_verification_bot/internal/models"
 )
 
+// ErrUserNotFound возвращается, если пользователя нет в таблице
+var ErrUserNotFound = errors.New("user not found")
+
 type SheetsService struct {
 	service       *sheets.Service
 	spreadsheetID string
+	cache         *userCache
+	limiter       *rateLimiter
+	maxAttempts   int
+	usersSheet    string
+	firstTab      string // первая вкладка, под tabs.mutex
+	usersGID      *int64
+	gidMutex      sync.Mutex
+	tabs          tabCache
+	// writes упорядочивает изменения служебных вкладок
+	writes sync.Mutex
 }
 
+// NewSheetsService создает клиент таблицы. Методы, обращающиеся к API,
+// принимают ctx вызывающего: его отмена прерывает ожидание квоты и повторы.
 func NewSheetsService(credentialsPath, spreadsheetID string) (*SheetsService, error) {
 	ctx := context.Background()
-	
+
 	service, err := sheets.NewService(ctx, option.WithCredentialsFile(credentialsPath))
 	if err != nil {
 		return nil, fmt.Errorf("unable to create sheets service: %v", err)
@@ -26,215 +47,349 @@ func NewSheetsService(credentialsPath, spreadsheetID string) (*SheetsService, er
 	return &SheetsService{
 		service:       service,
 		spreadsheetID: spreadsheetID,
+		cache:         newUserCache(defaultCacheTTL),
+		limiter:       newRateLimiter(defaultRequestsPerMinute),
+		maxAttempts:   defaultMaxAttempts,
 	}, nil
 }
 
-// SetupHeaders создает заголовки в таблице
-func (s *SheetsService) SetupHeaders() error {
-	headers := []interface{}{
-		"User ID", "Username", "Имя", "Фамилия", "Телефон", 
-		"Email", "Адрес", "Дата регистрации", "Статус", "Роль", "Админ комментарий",
+// SetCacheTTL задает время жизни локальной копии таблицы пользователей
+func (s *SheetsService) SetCacheTTL(ttl time.Duration) {
+	s.cache = newUserCache(ttl)
+}
+
+// SetUsersSheet задает имя вкладки с пользователями. Пустое имя означает
+// первую вкладку таблицы.
+func (s *SheetsService) SetUsersSheet(name string) {
+	s.usersSheet = name
+}
+
+// tabA1 добавляет к диапазону имя вкладки
+func tabA1(tab, a1 string) string {
+	if tab == "" {
+		return a1
 	}
+	return fmt.Sprintf("'%s'!%s", strings.ReplaceAll(tab, "'", "''"), a1)
+}
 
-	valueRange := &sheets.ValueRange{
-		Values: [][]interface{}{headers},
+// Invalidate сбрасывает локальную копию таблицы, следующий запрос прочитает ее заново
+func (s *SheetsService) Invalidate() {
+	s.cache.invalidate()
+
+	s.tabs.mutex.Lock()
+	s.tabs.tables = nil
+	s.tabs.mutex.Unlock()
+}
+
+// Sync перечитывает таблицу и обновляет кэш, если ее содержимое изменилось
+// вне бота. Возвращает true, если были обнаружены правки.
+func (s *SheetsService) Sync(ctx context.Context) (bool, error) {
+	values, err := s.fetchRows(ctx)
+	if err != nil {
+		return false, fmt.Errorf("unable to sync users: %v", err)
 	}
 
-	_, err := s.service.Spreadsheets.Values.Update(
-		s.spreadsheetID,
-		"A1:K1",
-		valueRange,
-	).ValueInputOption("RAW").Do()
+	s.cache.mutex.RLock()
+	// Пустой отпечаток означает, что после записи бота сверять не с чем
+	changed := s.cache.byID != nil && s.cache.fingerprint != "" &&
+		s.cache.fingerprint != fingerprintRows(values)
+	s.cache.mutex.RUnlock()
+
+	if changed {
+		s.cache.invalidate()
+	}
+	s.cache.replace(values)
+
+	return changed, nil
+}
+
+// fetchRows читает все строки таблицы пользователей
+func (s *SheetsService) fetchRows(ctx context.Context) ([][]interface{}, error) {
+	var resp *sheets.ValueRange
+	err := s.call(ctx, "read users", func(ctx context.Context) error {
+		var err error
+		resp, err = s.service.Spreadsheets.Values.Get(
+			s.spreadsheetID,
+			tabA1(s.usersSheet, usersRange),
+		).Context(ctx).Do()
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Values, nil
+}
+
+// loadUsers заполняет кэш из таблицы, если он пуст или устарел
+func (s *SheetsService) loadUsers(ctx context.Context) error {
+	if s.cache.fresh() {
+		return nil
+	}
 
+	values, err := s.fetchRows(ctx)
 	if err != nil {
-		return fmt.Errorf("unable to write headers: %v", err)
+		return err
 	}
 
+	s.cache.replace(values)
 	return nil
 }
 
+// writableColumns возвращает сопоставление колонок, если в таблицу можно писать
+func (s *SheetsService) writableColumns(ctx context.Context) (*columnMap, error) {
+	if err := s.loadUsers(ctx); err != nil {
+		return nil, err
+	}
+
+	columns := s.cache.columnMap()
+	if columns == nil {
+		return nil, fmt.Errorf("user table is not loaded")
+	}
+	if err := columns.requireWritable(); err != nil {
+		return nil, err
+	}
+	return columns, nil
+}
+
 // AddUser добавляет нового пользователя в таблицу
-func (s *SheetsService) AddUser(user *models.User) error {
-	values := []interface{}{
-		user.TelegramID,
-		user.Username,
-		user.FirstName,
-		user.LastName,
-		user.Phone,
-		user.Email,
-		user.Address,
-		user.RegisterDate.Format("2006-01-02 15:04:05"),
-		string(user.Status),
-		string(user.Role),
-		user.AdminComment,
+func (s *SheetsService) AddUser(ctx context.Context, user *models.User) error {
+	columns, err := s.writableColumns(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to add user: %v", err)
 	}
+	values := columns.userRow(user)
 
 	valueRange := &sheets.ValueRange{
 		Values: [][]interface{}{values},
 	}
 
-	_, err := s.service.Spreadsheets.Values.Append(
-		s.spreadsheetID,
-		"A:K",
-		valueRange,
-	).ValueInputOption("RAW").Do()
+	var resp *sheets.AppendValuesResponse
+	err = s.callOnce(ctx, "add user", func(ctx context.Context) error {
+		var err error
+		resp, err = s.service.Spreadsheets.Values.Append(
+			s.spreadsheetID,
+			tabA1(s.usersSheet, usersRange),
+			valueRange,
+		).ValueInputOption("RAW").Context(ctx).Do()
+		return err
+	})
 
 	if err != nil {
+		// Строка могла записаться: следующая попытка из журнала перечитает
+		// таблицу и не станет дописывать пользователя повторно
+		s.cache.invalidate()
 		return fmt.Errorf("unable to add user: %v", err)
 	}
 
+	row := 0
+	if resp.Updates != nil {
+		row = rowFromRange(resp.Updates.UpdatedRange)
+	}
+	s.cache.add(user, row, values)
+
 	return nil
 }
 
-// GetUser получает пользователя по Telegram ID
-func (s *SheetsService) GetUser(telegramID int64) (*models.User, error) {
-	resp, err := s.service.Spreadsheets.Values.Get(
-		s.spreadsheetID,
-		"A:K",
-	).Do()
+var rangeRowPattern = regexp.MustCompile(`![A-Z]+(\d+)`)
+
+// rowFromRange извлекает номер строки из диапазона вида "Лист1!A5:K5"
+func rowFromRange(a1 string) int {
+	m := rangeRowPattern.FindStringSubmatch(a1)
+	if m == nil {
+		return 0
+	}
+	row, _ := strconv.Atoi(m[1])
+	return row
+}
+
+// RowURL возвращает ссылку на строку пользователя в Google Таблице
+func (s *SheetsService) RowURL(ctx context.Context, telegramID int64) (string, bool) {
+	row, ok := s.cache.row(telegramID)
+	if !ok {
+		return "", false
+	}
+
+	gid, err := s.usersTabID(ctx)
+	if err != nil {
+		log.Printf("Error resolving users tab: %v", err)
+		return "", false
+	}
+
+	return fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/edit#gid=%d&range=%d:%d",
+		s.spreadsheetID, gid, row, row), true
+}
+
+// usersTabID возвращает идентификатор вкладки пользователей, запрашивая его один раз
+func (s *SheetsService) usersTabID(ctx context.Context) (int64, error) {
+	s.gidMutex.Lock()
+	defer s.gidMutex.Unlock()
+
+	if s.usersGID != nil {
+		return *s.usersGID, nil
+	}
 
+	ids, err := s.tabIDs(ctx)
 	if err != nil {
+		return 0, err
+	}
+
+	name := s.usersSheet
+	if name == "" {
+		name = s.firstTabName()
+	}
+	gid, ok := ids[name]
+	if !ok {
+		return 0, fmt.Errorf("tab %q not found", name)
+	}
+	s.usersGID = &gid
+	return gid, nil
+}
+
+// GetUser получает пользователя по Telegram ID
+func (s *SheetsService) GetUser(ctx context.Context, telegramID int64) (*models.User, error) {
+	if err := s.loadUsers(ctx); err != nil {
 		return nil, fmt.Errorf("unable to get user data: %v", err)
 	}
 
-	for i, row := range resp.Values {
-		if i == 0 { // Пропускаем заголовки
-			continue
-		}
-		
-		if len(row) > 0 {
-			if fmt.Sprintf("%v", row[0]) == fmt.Sprintf("%d", telegramID) {
-				user := &models.User{}
-				if len(row) > 0 { user.TelegramID = telegramID }
-				if len(row) > 1 { user.Username = fmt.Sprintf("%v", row[1]) }
-				if len(row) > 2 { user.FirstName = fmt.Sprintf("%v", row[2]) }
-				if len(row) > 3 { user.LastName = fmt.Sprintf("%v", row[3]) }
-				if len(row) > 4 { user.Phone = fmt.Sprintf("%v", row[4]) }
-				if len(row) > 5 { user.Email = fmt.Sprintf("%v", row[5]) }
-				if len(row) > 6 { user.Address = fmt.Sprintf("%v", row[6]) }
-				if len(row) > 8 { user.Status = models.UserStatus(fmt.Sprintf("%v", row[8])) }
-				if len(row) > 9 { user.Role = models.UserRole(fmt.Sprintf("%v", row[9])) }
-				if len(row) > 10 { user.AdminComment = fmt.Sprintf("%v", row[10]) }
-				
-				return user, nil
-			}
-		}
+	user, ok := s.cache.get(telegramID)
+	if !ok {
+		return nil, ErrUserNotFound
 	}
 
-	return nil, fmt.Errorf("user not found")
+	return user, nil
 }
 
-// UpdateUserStatus обновляет статус и роль пользователя
-func (s *SheetsService) UpdateUserStatus(telegramID int64, status models.UserStatus, role models.UserRole, comment string) error {
-	resp, err := s.service.Spreadsheets.Values.Get(
-		s.spreadsheetID,
-		"A:K",
-	).Do()
+// UpdateUserStatus обновляет статус, роль, комментарий и отметку об изменении
+// одним запросом BatchUpdate, чтобы строка не осталась обновленной наполовину
+func (s *SheetsService) UpdateUserStatus(ctx context.Context, telegramID int64, status models.UserStatus, role models.UserRole, comment, updatedBy string) error {
+	rowIndex, err := s.locateRow(ctx, telegramID)
+	if err != nil {
+		return err
+	}
+
+	columns, err := s.writableColumns(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to update status: %v", err)
+	}
+
+	updatedAt := time.Now()
+	cells := map[string]interface{}{
+		HeaderStatus:    string(status),
+		HeaderRole:      string(role),
+		HeaderUpdatedAt: formatTime(updatedAt),
+		HeaderUpdatedBy: updatedBy,
+	}
+	if comment != "" {
+		cells[HeaderAdminComment] = comment
+	}
+
+	var data []*sheets.ValueRange
+	for header, value := range cells {
+		letter, ok := columns.letter(header)
+		if !ok {
+			continue // необязательная колонка отсутствует
+		}
+		data = append(data, &sheets.ValueRange{
+			Range:  tabA1(s.usersSheet, fmt.Sprintf("%s%d", letter, rowIndex)),
+			Values: [][]interface{}{{value}},
+		})
+	}
 
+	request := &sheets.BatchUpdateValuesRequest{
+		ValueInputOption: "RAW",
+		Data:             data,
+	}
+	err = s.call(ctx, "update status", func(ctx context.Context) error {
+		_, err := s.service.Spreadsheets.Values.BatchUpdate(s.spreadsheetID, request).Context(ctx).Do()
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("unable to get data: %v", err)
+		return fmt.Errorf("unable to update status: %v", err)
 	}
 
-	for i, row := range resp.Values {
-		if i == 0 { // Пропускаем заголовки
+	s.cache.update(telegramID, cells, func(user *models.User) {
+		user.Status = status
+		user.Role = role
+		if comment != "" {
+			user.AdminComment = comment
+		}
+		user.UpdatedAt = updatedAt
+		user.UpdatedBy = updatedBy
+	})
+
+	return nil
+}
+
+// locateRow находит строку пользователя по индексу ID -> строка. Индекс
+// проверяется чтением одной ячейки и перестраивается, если таблицу
+// отсортировали или отредактировали вручную.
+func (s *SheetsService) locateRow(ctx context.Context, telegramID int64) (int, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		if attempt > 0 {
+			s.cache.invalidate()
+		}
+		if err := s.loadUsers(ctx); err != nil {
+			return 0, fmt.Errorf("unable to get data: %v", err)
+		}
+
+		rowIndex, ok := s.cache.row(telegramID)
+		if !ok {
 			continue
 		}
-		
-		if len(row) > 0 && fmt.Sprintf("%v", row[0]) == fmt.Sprintf("%d", telegramID) {
-			rowIndex := i + 1
-			
-			// Обновляем статус (колонка I)
-			statusRange := fmt.Sprintf("I%d", rowIndex)
-			statusValue := &sheets.ValueRange{
-				Values: [][]interface{}{{string(status)}},
-			}
-			_, err = s.service.Spreadsheets.Values.Update(
-				s.spreadsheetID,
-				statusRange,
-				statusValue,
-			).ValueInputOption("RAW").Do()
-			
-			if err != nil {
-				return fmt.Errorf("unable to update status: %v", err)
-			}
-
-			// Обновляем роль (колонка J)
-			roleRange := fmt.Sprintf("J%d", rowIndex)
-			roleValue := &sheets.ValueRange{
-				Values: [][]interface{}{{string(role)}},
-			}
-			_, err = s.service.Spreadsheets.Values.Update(
+		columns := s.cache.columnMap()
+		if columns == nil {
+			continue
+		}
+		idColumn, ok := columns.letter(HeaderTelegramID)
+		if !ok {
+			return 0, &MissingHeadersError{Headers: []string{HeaderTelegramID}}
+		}
+
+		var resp *sheets.ValueRange
+		err := s.call(ctx, "verify row", func(ctx context.Context) error {
+			var err error
+			resp, err = s.service.Spreadsheets.Values.Get(
 				s.spreadsheetID,
-				roleRange,
-				roleValue,
-			).ValueInputOption("RAW").Do()
-			
-			if err != nil {
-				return fmt.Errorf("unable to update role: %v", err)
-			}
-
-			// Обновляем комментарий (колонка K)
-			if comment != "" {
-				commentRange := fmt.Sprintf("K%d", rowIndex)
-				commentValue := &sheets.ValueRange{
-					Values: [][]interface{}{{comment}},
-				}
-				_, err = s.service.Spreadsheets.Values.Update(
-					s.spreadsheetID,
-					commentRange,
-					commentValue,
-				).ValueInputOption("RAW").Do()
-				
-				if err != nil {
-					return fmt.Errorf("unable to update comment: %v", err)
-				}
-			}
-
-			return nil
+				tabA1(s.usersSheet, fmt.Sprintf("%s%d", idColumn, rowIndex)),
+			).Context(ctx).Do()
+			return err
+		})
+		if err != nil {
+			return 0, fmt.Errorf("unable to get data: %v", err)
+		}
+
+		if len(resp.Values) > 0 && len(resp.Values[0]) > 0 &&
+			fmt.Sprintf("%v", resp.Values[0][0]) == fmt.Sprintf("%d", telegramID) {
+			return rowIndex, nil
 		}
 	}
 
-	return fmt.Errorf("user not found")
+	return 0, ErrUserNotFound
 }
 
 // GetAllUsers получает всех пользователей
-func (s *SheetsService) GetAllUsers() ([]*models.User, error) {
-	resp, err := s.service.Spreadsheets.Values.Get(
-		s.spreadsheetID,
-		"A:K",
-	).Do()
-
-	if err != nil {
+func (s *SheetsService) GetAllUsers(ctx context.Context) ([]*models.User, error) {
+	if err := s.loadUsers(ctx); err != nil {
 		return nil, fmt.Errorf("unable to get users: %v", err)
 	}
 
-	var users []*models.User
-	for i, row := range resp.Values {
-		if i == 0 { // Пропускаем заголовки
-			continue
-		}
-		
-		if len(row) > 0 {
-			user := &models.User{}
-			if len(row) > 0 { 
-				fmt.Sscanf(fmt.Sprintf("%v", row[0]), "%d", &user.TelegramID)
-			}
-			if len(row) > 1 { user.Username = fmt.Sprintf("%v", row[1]) }
-			if len(row) > 2 { user.FirstName = fmt.Sprintf("%v", row[2]) }
-			if len(row) > 3 { user.LastName = fmt.Sprintf("%v", row[3]) }
-			if len(row) > 4 { user.Phone = fmt.Sprintf("%v", row[4]) }
-			if len(row) > 5 { user.Email = fmt.Sprintf("%v", row[5]) }
-			if len(row) > 6 { user.Address = fmt.Sprintf("%v", row[6]) }
-			if len(row) > 7 { 
-				user.RegisterDate, _ = time.Parse("2006-01-02 15:04:05", fmt.Sprintf("%v", row[7]))
-			}
-			if len(row) > 8 { user.Status = models.UserStatus(fmt.Sprintf("%v", row[8])) }
-			if len(row) > 9 { user.Role = models.UserRole(fmt.Sprintf("%v", row[9])) }
-			if len(row) > 10 { user.AdminComment = fmt.Sprintf("%v", row[10]) }
-			
-			users = append(users, user)
-		}
+	return s.cache.all(), nil
+}
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// formatTime форматирует дату для таблицы, пустая дата остается пустой ячейкой
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
 	}
+	return t.Format(timeLayout)
+}
 
-	return users, nil
+// parseTime разбирает дату из ячейки таблицы
+func parseTime(value interface{}) time.Time {
+	t, _ := time.Parse(timeLayout, fmt.Sprintf("%v", value))
+	return t
 }
@@ -0,0 +1,291 @@
+package sheets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/sheets/v4"
+)
+
+// tabCache хранит содержимое служебных вкладок с тем же временем жизни,
+// что и кэш пользователей
+type tabCache struct {
+	mutex  sync.Mutex
+	tables map[string]*cachedTab
+}
+
+type cachedTab struct {
+	values   [][]interface{}
+	missing  bool // вкладки нет в таблице
+	loadedAt time.Time
+}
+
+// readTab возвращает сопоставление колонок и строки данных вкладки без
+// заголовка. Строка data[i] находится в таблице на строке i+2. Отсутствующая
+// вкладка читается как пустая.
+func (s *SheetsService) readTab(ctx context.Context, tab string) (*columnMap, [][]interface{}, error) {
+	values, _, err := s.tabValues(ctx, tab)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(values) == 0 {
+		return newColumnMap(nil), nil, nil
+	}
+	return newColumnMap(values[0]), values[1:], nil
+}
+
+// tabValues возвращает содержимое вкладки из кэша или из таблицы. То, что
+// вкладки нет, тоже кэшируется: иначе каждое чтение необязательной вкладки
+// тратило бы квоту на заведомо неудачный запрос.
+func (s *SheetsService) tabValues(ctx context.Context, tab string) ([][]interface{}, bool, error) {
+	s.tabs.mutex.Lock()
+	cached, ok := s.tabs.tables[tab]
+	s.tabs.mutex.Unlock()
+	if ok && time.Since(cached.loadedAt) < s.cache.ttl {
+		return cached.values, cached.missing, nil
+	}
+
+	var resp *sheets.ValueRange
+	err := s.call(ctx, "read "+tab, func(ctx context.Context) error {
+		var err error
+		resp, err = s.service.Spreadsheets.Values.Get(s.spreadsheetID, tabA1(tab, usersRange)).Context(ctx).Do()
+		return err
+	})
+	missing := isMissingTab(err)
+	if err != nil && !missing {
+		return nil, false, fmt.Errorf("unable to read %s: %v", tab, err)
+	}
+
+	var values [][]interface{}
+	if !missing {
+		values = resp.Values
+	}
+	s.tabs.mutex.Lock()
+	if s.tabs.tables == nil {
+		s.tabs.tables = make(map[string]*cachedTab)
+	}
+	s.tabs.tables[tab] = &cachedTab{values: values, missing: missing, loadedAt: time.Now()}
+	s.tabs.mutex.Unlock()
+	return values, missing, nil
+}
+
+// isMissingTab определяет ответ API на чтение несуществующей вкладки
+func isMissingTab(err error) bool {
+	var apiErr *googleapi.Error
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest &&
+		strings.Contains(apiErr.Message, "Unable to parse range")
+}
+
+// invalidateTab сбрасывает кэш вкладки после записи
+func (s *SheetsService) invalidateTab(tab string) {
+	s.tabs.mutex.Lock()
+	defer s.tabs.mutex.Unlock()
+
+	delete(s.tabs.tables, tab)
+}
+
+// editTab выполняет edit над свежим содержимым вкладки. Изменения служебных
+// вкладок идут по одному: номера строк, найденные в edit, остаются верными,
+// пока edit их не обновит или не удалит. Отсутствующая вкладка создается
+// с заголовками required.
+func (s *SheetsService) editTab(ctx context.Context, tab string, required []string, edit func(columns *columnMap, rows [][]interface{}) error) error {
+	s.writes.Lock()
+	defer s.writes.Unlock()
+
+	s.invalidateTab(tab)
+	values, missing, err := s.tabValues(ctx, tab)
+	if err != nil {
+		return err
+	}
+	if missing && len(required) > 0 {
+		if values, err = s.createTab(ctx, tab, required); err != nil {
+			return err
+		}
+	}
+
+	columns, rows := newColumnMap(nil), [][]interface{}(nil)
+	if len(values) > 0 {
+		columns, rows = newColumnMap(values[0]), values[1:]
+	}
+	if absent := columns.missing(required); len(absent) > 0 {
+		return &MissingHeadersError{Headers: absent}
+	}
+	return edit(columns, rows)
+}
+
+// createTab создает вкладку со строкой заголовков и возвращает ее содержимое
+func (s *SheetsService) createTab(ctx context.Context, tab string, headers []string) ([][]interface{}, error) {
+	if _, err := s.addTab(ctx, tab); err != nil {
+		return nil, err
+	}
+
+	row := make([]interface{}, len(headers))
+	for i, header := range headers {
+		row[i] = header
+	}
+	err := s.call(ctx, "write headers", func(ctx context.Context) error {
+		_, err := s.service.Spreadsheets.Values.Update(
+			s.spreadsheetID,
+			tabA1(tab, "A1"),
+			&sheets.ValueRange{Values: [][]interface{}{row}},
+		).ValueInputOption("RAW").Context(ctx).Do()
+		return err
+	})
+	s.invalidateTab(tab)
+	if err != nil {
+		return nil, fmt.Errorf("unable to write headers of %s: %v", tab, err)
+	}
+
+	log.Printf("Created tab %s", tab)
+	return [][]interface{}{row}, nil
+}
+
+// appendTabRow дописывает строку во вкладку, раскладывая значения по заголовкам
+func (s *SheetsService) appendTabRow(ctx context.Context, tab string, required []string, cells map[string]interface{}) error {
+	return s.editTab(ctx, tab, required, func(columns *columnMap, _ [][]interface{}) error {
+		return s.appendRow(ctx, tab, columns, cells)
+	})
+}
+
+// appendRow дописывает строку; вызывается внутри editTab
+func (s *SheetsService) appendRow(ctx context.Context, tab string, columns *columnMap, cells map[string]interface{}) error {
+	row := make([]interface{}, len(columns.headers))
+	for i := range row {
+		row[i] = ""
+	}
+	for header, value := range cells {
+		if i, ok := columns.column(header); ok {
+			row[i] = value
+		}
+	}
+
+	err := s.callOnce(ctx, "append "+tab, func(ctx context.Context) error {
+		_, err := s.service.Spreadsheets.Values.Append(
+			s.spreadsheetID,
+			tabA1(tab, usersRange),
+			&sheets.ValueRange{Values: [][]interface{}{row}},
+		).ValueInputOption("RAW").Context(ctx).Do()
+		return err
+	})
+	s.invalidateTab(tab)
+	if err != nil {
+		return fmt.Errorf("unable to append to %s: %v", tab, err)
+	}
+	return nil
+}
+
+// rowMatch выбирает строки вкладки
+type rowMatch func(columns *columnMap, row []interface{}) bool
+
+// byKey выбирает строки, у которых в колонке header записано key
+func byKey(header, key string) rowMatch {
+	return func(columns *columnMap, row []interface{}) bool {
+		return columns.cell(row, header) == key
+	}
+}
+
+// upsertTabRow обновляет первую строку, выбранную match, или дописывает новую
+func (s *SheetsService) upsertTabRow(ctx context.Context, tab string, required []string, match rowMatch, cells map[string]interface{}) error {
+	return s.editTab(ctx, tab, required, func(columns *columnMap, rows [][]interface{}) error {
+		for i, row := range rows {
+			if match(columns, row) {
+				return s.updateRow(ctx, tab, columns, i+2, cells)
+			}
+		}
+		return s.appendRow(ctx, tab, columns, cells)
+	})
+}
+
+// deleteTabRows удаляет все строки, выбранные match
+func (s *SheetsService) deleteTabRows(ctx context.Context, tab string, match rowMatch) error {
+	return s.editTab(ctx, tab, nil, func(columns *columnMap, rows [][]interface{}) error {
+		var rowIndexes []int
+		for i, row := range rows {
+			if match(columns, row) {
+				rowIndexes = append(rowIndexes, i+2)
+			}
+		}
+		if len(rowIndexes) == 0 {
+			return nil
+		}
+		return s.deleteRows(ctx, tab, rowIndexes)
+	})
+}
+
+// updateRow обновляет ячейки строки вкладки одним запросом; вызывается внутри editTab
+func (s *SheetsService) updateRow(ctx context.Context, tab string, columns *columnMap, rowIndex int, cells map[string]interface{}) error {
+	var data []*sheets.ValueRange
+	for header, value := range cells {
+		letter, ok := columns.letter(header)
+		if !ok {
+			continue
+		}
+		data = append(data, &sheets.ValueRange{
+			Range:  tabA1(tab, fmt.Sprintf("%s%d", letter, rowIndex)),
+			Values: [][]interface{}{{value}},
+		})
+	}
+
+	request := &sheets.BatchUpdateValuesRequest{ValueInputOption: "RAW", Data: data}
+	err := s.call(ctx, "update "+tab, func(ctx context.Context) error {
+		_, err := s.service.Spreadsheets.Values.BatchUpdate(s.spreadsheetID, request).Context(ctx).Do()
+		return err
+	})
+	s.invalidateTab(tab)
+	if err != nil {
+		return fmt.Errorf("unable to update %s: %v", tab, err)
+	}
+	return nil
+}
+
+// deleteRows удаляет строки вкладки одним запросом; вызывается внутри
+// editTab. Строки удаляются снизу вверх, чтобы номера оставшихся не
+// сдвигались. Запрос не повторяется после таймаута: если он выполнился,
+// повтор удалил бы чужие строки.
+func (s *SheetsService) deleteRows(ctx context.Context, tab string, rowIndexes []int) error {
+	tabIDs, err := s.tabIDs(ctx)
+	if err != nil {
+		return err
+	}
+	sheetID, ok := tabIDs[tab]
+	if !ok {
+		return fmt.Errorf("tab %s not found", tab)
+	}
+
+	sorted := append([]int(nil), rowIndexes...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+
+	var requests []*sheets.Request
+	for _, rowIndex := range sorted {
+		requests = append(requests, &sheets.Request{
+			DeleteDimension: &sheets.DeleteDimensionRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:    sheetID,
+					Dimension:  "ROWS",
+					StartIndex: int64(rowIndex - 1),
+					EndIndex:   int64(rowIndex),
+				},
+			},
+		})
+	}
+
+	err = s.callOnce(ctx, "delete rows "+tab, func(ctx context.Context) error {
+		_, err := s.service.Spreadsheets.BatchUpdate(s.spreadsheetID, &sheets.BatchUpdateSpreadsheetRequest{
+			Requests: requests,
+		}).Context(ctx).Do()
+		return err
+	})
+	s.invalidateTab(tab)
+	if err != nil {
+		return fmt.Errorf("unable to delete rows of %s: %v", tab, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package sheets
+
+import (
+	"context"
+	"time"
+)
+
+// TemplatesTab служебная вкладка с шаблонами сообщений, измененными через /templates
+const TemplatesTab = "Templates"
+
+// Заголовки вкладки шаблонов
+const (
+	HeaderTemplateKey  = "Шаблон"
+	HeaderTemplateText = "Текст"
+	HeaderTemplateDate = "Изменено"
+	HeaderTemplateBy   = "Кем изменено"
+)
+
+var templateHeaders = []string{HeaderTemplateKey, HeaderTemplateText, HeaderTemplateDate, HeaderTemplateBy}
+
+// GetTemplates возвращает шаблоны: ключ -> текст. Пустой текст означает,
+// что шаблон сброшен к значению из конфигурации.
+func (s *SheetsService) GetTemplates(ctx context.Context) (map[string]string, error) {
+	columns, rows, err := s.readTab(ctx, TemplatesTab)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]string)
+	for _, row := range rows {
+		key := columns.cell(row, HeaderTemplateKey)
+		if key == "" {
+			continue
+		}
+		templates[key] = columns.cell(row, HeaderTemplateText)
+	}
+	return templates, nil
+}
+
+// SetTemplate сохраняет текст шаблона, заменяя прежний
+func (s *SheetsService) SetTemplate(ctx context.Context, key, text, updatedBy string) error {
+	return s.upsertTabRow(ctx, TemplatesTab, templateHeaders, byKey(HeaderTemplateKey, key), map[string]interface{}{
+		HeaderTemplateKey:  key,
+		HeaderTemplateText: text,
+		HeaderTemplateDate: formatTime(time.Now()),
+		HeaderTemplateBy:   updatedBy,
+	})
+}
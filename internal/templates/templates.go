@@ -0,0 +1,249 @@
+// Package templates хранит шаблоны сообщений, которые администратор может
+// менять без правки кода: приветствие, подтверждение заявки и решения по
+// ней. Шаблоны пишутся на text/template и проверяются перед сохранением.
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"telegram_verification_bot/internal/models"
+)
+
+// Name название шаблона
+type Name string
+
+const (
+	Welcome    Name = "welcome"    // приветствие по /start
+	Registered Name = "registered" // подтверждение отправки заявки
+	Approved   Name = "approved"   // заявка одобрена
+	Rejected   Name = "rejected"   // заявка отклонена
+)
+
+// Names все шаблоны
+var Names = []Name{Welcome, Registered, Approved, Rejected}
+
+// Valid проверяет, что шаблон с таким названием существует
+func (n Name) Valid() bool {
+	for _, name := range Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxLength ограничение Telegram на длину сообщения
+const MaxLength = 4096
+
+// Data поля пользователя, доступные в шаблоне: {{.FirstName}}, {{.Role}} и т.д.
+type Data struct {
+	TelegramID   int64
+	Username     string
+	FirstName    string
+	LastName     string
+	Phone        string
+	Email        string
+	Address      string
+	Status       string
+	Role         string // название роли на языке получателя
+	Reason       string // причина отклонения
+	RegisterDate time.Time
+}
+
+// Fields перечисляет поля Data для подсказки администратору
+var Fields = []string{
+	"TelegramID", "Username", "FirstName", "LastName", "Phone", "Email",
+	"Address", "Status", "Role", "Reason", "RegisterDate",
+}
+
+// FromUser заполняет данные шаблона из записи пользователя
+func FromUser(user *models.User) Data {
+	return Data{
+		TelegramID:   user.TelegramID,
+		Username:     user.Username,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		Phone:        user.Phone,
+		Email:        user.Email,
+		Address:      user.Address,
+		Status:       string(user.Status),
+		Role:         string(user.Role),
+		Reason:       user.AdminComment,
+		RegisterDate: user.RegisterDate,
+	}
+}
+
+// Sample пример данных для проверки и предпросмотра шаблона
+func Sample() Data {
+	return Data{
+		TelegramID:   123456789,
+		Username:     "ivanov",
+		FirstName:    "Иван",
+		LastName:     "Иванов",
+		Phone:        "+71234567890",
+		Email:        "ivanov@example.com",
+		Address:      "GFC P11",
+		Status:       string(models.StatusApproved),
+		Role:         string(models.RoleResident),
+		Reason:       "Не указана",
+		RegisterDate: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+}
+
+// Key ключ шаблона: название и, если шаблон только для одного языка, код языка
+func Key(name Name, lang string) string {
+	if lang == "" {
+		return string(name)
+	}
+	return string(name) + "." + lang
+}
+
+// ParseKey разбирает ключ вида "welcome" или "welcome.en". Суффикс должен
+// быть одним из языков languages, иначе шаблон никогда бы не применился.
+func ParseKey(key string, languages []string) (Name, string, error) {
+	name, lang, _ := strings.Cut(strings.ToLower(strings.TrimSpace(key)), ".")
+	if !Name(name).Valid() {
+		return "", "", fmt.Errorf("unknown template %q", key)
+	}
+	if lang != "" && !contains(languages, lang) {
+		return "", "", fmt.Errorf("unknown language %q in template %q", lang, key)
+	}
+	return Name(name), lang, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse разбирает шаблон и выполняет его на примере данных, чтобы ошибка
+// в шаблоне обнаружилась до сохранения, а не при отправке сообщения
+func Parse(key, text string) (*template.Template, error) {
+	tmpl, err := template.New(key).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, Sample()); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(out.String()) == "" {
+		return nil, fmt.Errorf("template renders empty text")
+	}
+	if out.Len() > MaxLength {
+		return nil, fmt.Errorf("template renders %d bytes, limit is %d", out.Len(), MaxLength)
+	}
+	return tmpl, nil
+}
+
+// Origin откуда взят шаблон
+const (
+	OriginConfig = "config"
+	OriginSheet  = "таблица"
+)
+
+type item struct {
+	source string
+	origin string
+	tmpl   *template.Template
+}
+
+// Set проверенные шаблоны по ключам
+type Set struct {
+	mutex     sync.RWMutex
+	items     map[string]*item
+	languages []string // языки, допустимые в суффиксе ключа
+}
+
+func NewSet(languages []string) *Set {
+	return &Set{items: make(map[string]*item), languages: languages}
+}
+
+// Put проверяет шаблон и сохраняет его под ключом key
+func (s *Set) Put(key, text, origin string) error {
+	name, lang, err := ParseKey(key, s.languages)
+	if err != nil {
+		return err
+	}
+	key = Key(name, lang)
+
+	tmpl, err := Parse(key, text)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.items[key] = &item{source: text, origin: origin, tmpl: tmpl}
+	return nil
+}
+
+// Replace заменяет шаблоны набора шаблонами other
+func (s *Set) Replace(other *Set) {
+	other.mutex.RLock()
+	items := make(map[string]*item, len(other.items))
+	for key, it := range other.items {
+		items[key] = it
+	}
+	other.mutex.RUnlock()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.items = items
+}
+
+// Source возвращает текст шаблона и его происхождение
+func (s *Set) Source(key string) (string, string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	it, ok := s.items[key]
+	if !ok {
+		return "", "", false
+	}
+	return it.source, it.origin, true
+}
+
+// Keys возвращает ключи заданных шаблонов по алфавиту
+func (s *Set) Keys() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var keys []string
+	for key := range s.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Render выполняет шаблон для языка lang, а если его нет — общий шаблон.
+// Возвращает пустую строку, если шаблон не задан.
+func (s *Set) Render(name Name, lang string, data Data) (string, error) {
+	s.mutex.RLock()
+	it, ok := s.items[Key(name, lang)]
+	if !ok {
+		it, ok = s.items[Key(name, "")]
+	}
+	s.mutex.RUnlock()
+	if !ok {
+		return "", nil
+	}
+
+	var out bytes.Buffer
+	if err := it.tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,66 @@
+package templates
+
+import "testing"
+
+func TestParseKey(t *testing.T) {
+	languages := []string{"en", "ru"}
+	tests := []struct {
+		key     string
+		name    Name
+		lang    string
+		wantErr bool
+	}{
+		{"welcome", Welcome, "", false},
+		{" Approved ", Approved, "", false},
+		{"welcome.en", Welcome, "en", false},
+		{"REJECTED.RU", Rejected, "ru", false},
+		{"welcome.xx", "", "", true},
+		{"welcome.a.b", "", "", true},
+		{"welcome.", Welcome, "", false},
+		{"goodbye", "", "", true},
+		{"", "", "", true},
+	}
+	for _, tt := range tests {
+		name, lang, err := ParseKey(tt.key, languages)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseKey(%q): err = %v, wantErr %v", tt.key, err, tt.wantErr)
+			continue
+		}
+		if name != tt.name || lang != tt.lang {
+			t.Errorf("ParseKey(%q) = %q, %q, want %q, %q", tt.key, name, lang, tt.name, tt.lang)
+		}
+	}
+}
+
+func TestSetRender(t *testing.T) {
+	set := NewSet([]string{"en", "ru"})
+	if err := set.Put("welcome", "Привет, {{.FirstName}}", OriginConfig); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := set.Put("welcome.en", "Hello, {{.FirstName}}", OriginConfig); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	for _, bad := range []string{"{{.Unknown}}", "{{", "   "} {
+		if err := set.Put("approved", bad, OriginConfig); err == nil {
+			t.Errorf("Put(%q): expected error", bad)
+		}
+	}
+
+	data := Data{FirstName: "Анна"}
+	tests := []struct {
+		name Name
+		lang string
+		want string
+	}{
+		{Welcome, "en", "Hello, Анна"},
+		{Welcome, "ru", "Привет, Анна"},
+		{Welcome, "de", "Привет, Анна"},
+		{Approved, "ru", ""},
+	}
+	for _, tt := range tests {
+		got, err := set.Render(tt.name, tt.lang, data)
+		if err != nil || got != tt.want {
+			t.Errorf("Render(%s, %s) = %q, %v, want %q", tt.name, tt.lang, got, err, tt.want)
+		}
+	}
+}